@@ -0,0 +1,107 @@
+package REST
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"net/http"
+)
+
+//The error schema of the v1 API. Status repeats the HTTP status code. Field names the invalid request field, Check and
+//Reason the failed check of a tx, see client.CheckTx. Details holds data of a partly failed request, e.g. the broadcast
+//results if the quorum was not met.
+type ApiError struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Field   string      `json:"field,omitempty"`
+	Check   string      `json:"check,omitempty"`
+	Reason  string      `json:"reason,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error *ApiError `json:"error"`
+}
+
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+//The legacy endpoints respond with status 200 and report the error's status in the body.
+func (e *ApiError) legacyResponse(content []Content) JsonResponse {
+	if len(e.Check) > 0 {
+		content = append(content, Content{"check", e.Check})
+		content = append(content, Content{"reason", e.Reason})
+	}
+
+	return JsonResponse{e.Status, e.Message, content}
+}
+
+func invalidField(field string, format string, args ...interface{}) *ApiError {
+	return &ApiError{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...), Field: field}
+}
+
+//A request to the miners failed. Returns 504 if they did not answer in time and 502 otherwise.
+func networkError(err error, format string, args ...interface{}) *ApiError {
+	status := http.StatusBadGateway
+	if errors.Is(err, network.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	}
+
+	return &ApiError{Status: status, Message: fmt.Sprintf("%v: %v", fmt.Sprintf(format, args...), err)}
+}
+
+func sendJson(w http.ResponseWriter, status int, resp interface{}) {
+	js, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+func sendError(w http.ResponseWriter, apiErr *ApiError) {
+	sendJson(w, apiErr.Status, ErrorResponse{apiErr})
+}
+
+//Unknown fields are rejected, so misspelled fields do not silently fall back to their zero value.
+func decodeBody(req *http.Request, body interface{}) *ApiError {
+	if req.Body == nil {
+		return &ApiError{Status: http.StatusBadRequest, Message: "Please send a request body"}
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return &ApiError{Status: http.StatusBadRequest, Message: fmt.Sprintf("Invalid request body: %v", err)}
+	}
+
+	return nil
+}
+
+//Decode the hex encoded field, which must have exactly the given length in bytes.
+func decodeHexField(field string, value string, length int) ([]byte, *ApiError) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) != length {
+		return nil, invalidField(field, "Invalid %v %v, expected %v hex characters", field, value, 2*length)
+	}
+
+	return decoded, nil
+}
+
+func decodeHash(field string, value string) (hash [32]byte, apiErr *ApiError) {
+	decoded, apiErr := decodeHexField(field, value, 32)
+	if apiErr != nil {
+		return hash, apiErr
+	}
+
+	copy(hash[:], decoded)
+
+	return hash, nil
+}
@@ -1,12 +1,16 @@
 package REST
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/bazo-blockchain/bazo-client/client"
 	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/gorilla/mux"
 	"math/big"
 	"net/http"
+	"strconv"
 )
 
 func GetAccountEndpoint(w http.ResponseWriter, req *http.Request) {
@@ -22,29 +26,104 @@ func GetAccountEndpoint(w http.ResponseWriter, req *http.Request) {
 	if len(param) == 64 {
 		copy(addressHash[:], pubKeyInt.Bytes())
 
-		network.AccReq(false, addressHash)
-
-		accI, _ := network.Fetch(network.AccChan)
-		acc := accI.(*protocol.Account)
+		acc, _ := network.GetAccount(req.Context(), false, addressHash)
+		if acc == nil {
+			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("Account %x does not exist.", addressHash[:8]), nil})
+			return
+		}
 
 		address = acc.Address
 	} else if len(param) == 128 {
 		copy(address[:], pubKeyInt.Bytes())
 		addressHash = protocol.SerializeHashContent(address)
 	}
-	acc, lastTenTx, err := client.GetAccount(addressHash)
+	acc, history, err := client.GetAccount(req.Context(), addressHash)
 	if err != nil {
 		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
 	} else {
 		var content []Content
 		content = append(content, Content{"account", acc})
 
-		for _, tx := range lastTenTx {
-			if tx != nil {
-				content = append(content, Content{"inbound", tx})
+		inbound := 0
+		for _, entry := range history {
+			if entry.FundsTx != nil && entry.Direction == client.DIRECTION_INBOUND && inbound < client.HISTORY_DEFAULT_LIMIT {
+				content = append(content, Content{"inbound", entry.FundsTx})
+				inbound++
 			}
 		}
 
 		SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
 	}
 }
+
+func GetAccountTxsEndpoint(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	address, err := getAddress(req.Context(), params["id"])
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, err.Error(), nil})
+		return
+	}
+
+	var limit int
+	if limitParam := req.URL.Query().Get("limit"); len(limitParam) > 0 {
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 {
+			SendJsonResponse(w, JsonResponse{http.StatusBadRequest, fmt.Sprintf("Invalid limit %v", limitParam), nil})
+			return
+		}
+	}
+
+	cursor := req.URL.Query().Get("cursor")
+	if err := client.ValidateHistoryCursor(cursor); err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, err.Error(), nil})
+		return
+	}
+
+	history, nextCursor, err := client.GetAccountHistory(req.Context(), address, cursor, limit)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
+
+	var content []Content
+	for _, entry := range history {
+		content = append(content, Content{"tx", entry})
+	}
+
+	if len(nextCursor) > 0 {
+		content = append(content, Content{"nextCursor", nextCursor})
+	}
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
+
+//The id is either the 64 character address hash or the 128 character address.
+func getAddress(ctx context.Context, id string) (address [32]byte, err error) {
+	pubKeyInt, ok := new(big.Int).SetString(id, 16)
+	if !ok {
+		return address, errors.New(fmt.Sprintf("Invalid account id %v", id))
+	}
+
+	switch len(id) {
+	case 64:
+		var addressHash [32]byte
+		copy(addressHash[:], pubKeyInt.Bytes())
+
+		acc, err := network.GetAccount(ctx, false, addressHash)
+		if err != nil {
+			return address, err
+		}
+
+		if acc == nil {
+			return address, errors.New(fmt.Sprintf("Account %x does not exist.", addressHash[:8]))
+		}
+
+		address = acc.Address
+	case 128:
+		copy(address[:], pubKeyInt.Bytes())
+	default:
+		return address, errors.New(fmt.Sprintf("Invalid account id %v", id))
+	}
+
+	return address, nil
+}
@@ -0,0 +1,20 @@
+package REST
+
+import (
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"net/http"
+)
+
+func GetFeeEndpoint(w http.ResponseWriter, req *http.Request) {
+	estimate, err := client.EstimateFee(req.Context())
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("Fee estimation failed: %v", err), nil})
+		return
+	}
+
+	var content []Content
+	content = append(content, Content{"fee", estimate})
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
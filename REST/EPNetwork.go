@@ -0,0 +1,20 @@
+package REST
+
+import (
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"net/http"
+)
+
+func GetNetworkParametersEndpoint(w http.ResponseWriter, req *http.Request) {
+	parameters, err := client.GetNetworkParameters(req.Context())
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("Could not compute the network parameters: %v", err), nil})
+		return
+	}
+
+	var content []Content
+	content = append(content, Content{"parameters", parameters})
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
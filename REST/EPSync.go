@@ -0,0 +1,13 @@
+package REST
+
+import (
+	"github.com/bazo-blockchain/bazo-client/client"
+	"net/http"
+)
+
+func GetSyncStatusEndpoint(w http.ResponseWriter, req *http.Request) {
+	var content []Content
+	content = append(content, Content{"status", client.GetSyncStatus()})
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
@@ -1,11 +1,9 @@
 package REST
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/client"
 	"github.com/bazo-blockchain/bazo-client/network"
@@ -19,7 +17,11 @@ import (
 	"sync"
 )
 
-var mutex = &sync.Mutex{};
+//Guards sendingTxs, the unsigned txs which are being signed and sent.
+var (
+	mutex      = &sync.Mutex{}
+	sendingTxs = make(map[[32]byte]bool)
+)
 
 type JsonResponse struct {
 	Code    int       `json:"code,omitempty"`
@@ -71,36 +73,13 @@ const (
 
 )
 
+//The private key of a new account must never leave the machine of its owner, so the REST service does not generate it.
+//The key is created by the caller, who passes its public key to POST /v1/txs/acc. Unlike the other legacy endpoints,
+//the removed endpoint responds with its status code and an ErrorResponse.
 func CreateAccTxEndpoint(w http.ResponseWriter, req *http.Request) {
 	logger.Println("Incoming createAcc request")
 
-	params := mux.Vars(req)
-
-	header, _ := strconv.Atoi(params["header"])
-	fee, _ := strconv.Atoi(params["fee"])
-
-	tx := protocol.AccTx{
-		Header: byte(header),
-		Fee:    uint64(fee),
-	}
-
-	issuerInt, _ := new(big.Int).SetString(params["issuer"], 16)
-	copy(tx.Issuer[:], issuerInt.Bytes())
-
-	newAccAddress, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	copy(tx.PubKey[:32], newAccAddress.PublicKey.X.Bytes())
-	copy(tx.PubKey[32:], newAccAddress.PublicKey.Y.Bytes())
-
-	txHash := tx.Hash()
-	client.UnsignedAccTx[txHash] = &tx
-
-	var content []Content
-	content = append(content, Content{"PubKey1", hex.EncodeToString(tx.PubKey[:32])})
-	content = append(content, Content{"PubKey2", hex.EncodeToString(tx.PubKey[32:])})
-	content = append(content, Content{"PrivKey", hex.EncodeToString(newAccAddress.D.Bytes())})
-	content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
-
-	SendJsonResponse(w, JsonResponse{http.StatusOK, "AccTx successfully created.", content})
+	sendError(w, &ApiError{Status: http.StatusGone, Message: "Keys are not generated by the server anymore. Create the key locally and use POST /v1/txs/acc."})
 }
 
 func CreateAccTxEndpointWithPubKey(w http.ResponseWriter, req *http.Request) {
@@ -121,8 +100,11 @@ func CreateAccTxEndpointWithPubKey(w http.ResponseWriter, req *http.Request) {
 	issuerInt, _ := new(big.Int).SetString(params["issuer"], 16)
 	copy(tx.Issuer[:], issuerInt.Bytes())
 
-	txHash := tx.Hash()
-	client.UnsignedAccTx[txHash] = &tx
+	txHash, err := client.AddUnsignedTx(&tx, p2p.ACCTX_BRDCST, tx.Issuer)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
 
 	var content []Content
 	content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
@@ -173,10 +155,12 @@ func CreateAccTxEndpointWithPubKeyIoT(w http.ResponseWriter, req *http.Request)
 	copy(tx.PubKey[:], toPub[:])
 	copy(tx.Issuer[:], issuer[:])
 
-	txHash := tx.Hash()
-	mutex.Lock()
-	client.UnsignedAccTx[txHash] = &tx
-	mutex.Unlock()
+	txHash, err := client.AddUnsignedTx(&tx, p2p.ACCTX_BRDCST, issuer)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
+
 	var content []Content
 	content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
 	SendJsonResponse(w, JsonResponse{http.StatusOK, "AccTx successfully created.", content})
@@ -227,10 +211,12 @@ func CreateFundsTxIoT(w http.ResponseWriter, req *http.Request) {
 	tx.To = protocol.SerializeHashContent(tx.To)
 	tx.From = protocol.SerializeHashContent(tx.From)
 
-	txHash := tx.Hash()
-	mutex.Lock()
-	client.UnsignedFundsTx[txHash] = &tx
-	mutex.Unlock()
+	txHash, err := client.AddUnsignedTx(&tx, p2p.FUNDSTX_BRDCST, fromPubKey)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
+
 	var content []Content
 	content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
 	SendJsonResponse(w, JsonResponse{http.StatusOK, "AccTx successfully created.", content})
@@ -247,6 +233,21 @@ func CreateConfigTxEndpoint(w http.ResponseWriter, req *http.Request) {
 	fee, _ := strconv.Atoi(params["fee"])
 	txCnt, _ := strconv.Atoi(params["txCnt"])
 
+	//ConfigTxs do not name their issuer, their signature is verified against the root's public key.
+	var root [32]byte
+	rootParam := req.URL.Query().Get("root")
+	if len(rootParam) == 0 {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, "Missing root address, it is required to verify the ConfigTx", nil})
+		return
+	}
+
+	rootBytes, err := hex.DecodeString(rootParam)
+	if err != nil || len(rootBytes) != 32 {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, fmt.Sprintf("Invalid root address %v", rootParam), nil})
+		return
+	}
+	copy(root[:], rootBytes)
+
 	tx := protocol.ConfigTx{
 		Header:  byte(header),
 		Id:      uint8(id),
@@ -255,8 +256,11 @@ func CreateConfigTxEndpoint(w http.ResponseWriter, req *http.Request) {
 		TxCnt:   uint8(txCnt),
 	}
 
-	txHash := tx.Hash()
-	client.UnsignedConfigTx[txHash] = &tx
+	txHash, err := client.AddUnsignedTx(&tx, p2p.CONFIGTX_BRDCST, root)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
 
 	var content []Content
 	content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
@@ -294,8 +298,11 @@ func CreateFundsTxEndpoint(w http.ResponseWriter, req *http.Request) {
 		Data:   []byte{},
 	}
 	//fmt.Println("FUNDS", tx)
-	txHash := tx.Hash()
-	client.UnsignedFundsTx[txHash] = &tx
+	txHash, err := client.AddUnsignedTx(&tx, p2p.FUNDSTX_BRDCST, fromPub)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
 	//logger.Printf("New unsigned tx: %x\n", txHash)
 
 	var content []Content
@@ -352,8 +359,11 @@ func CreateFundsWithDataTxEndpoint(w http.ResponseWriter, req *http.Request) {
 		Data:   data[:],
 	}
 	//fmt.Println("FUNDS", tx)
-	txHash := tx.Hash()
-	client.UnsignedFundsTx[txHash] = &tx
+	txHash, err := client.AddUnsignedTx(&tx, p2p.FUNDSTX_BRDCST, fromPub)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		return
+	}
 	//logger.Printf("New unsigned tx: %x\n", txHash)
 
 	var content []Content
@@ -366,7 +376,12 @@ func sendTxEndpoint(w http.ResponseWriter, req *http.Request, txType int) {
 
 	var txHash [32]byte
 	var txSign [64]byte
-	var err error
+
+	quorum, err := getQuorum(req)
+	if err != nil {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, err.Error(), nil})
+		return
+	}
 
 	txHashInt, _ := new(big.Int).SetString(params["txHash"], 16)
 	copy(txHash[:], txHashInt.Bytes())
@@ -374,83 +389,185 @@ func sendTxEndpoint(w http.ResponseWriter, req *http.Request, txType int) {
 	copy(txSign[:], txSignInt.Bytes())
 	//logger.Printf("Incoming sendTx request for tx: %x", txHash)
 
-	switch txType {
+	results, apiErr := sendUnsignedTx(req, txHash, txSign, uint8(txType), quorum)
+
+	var content []Content
+	for _, result := range results {
+		content = append(content, Content{"broadcast", result})
+	}
+
+	if apiErr == nil {
+		SendJsonResponse(w, JsonResponse{http.StatusOK, fmt.Sprintf("Transaction %x successfully sent to network.", txHash[:8]), content})
+	} else {
+		//logger.Printf("Sending tx failed: %v\n", apiErr.Message)
+		SendJsonResponse(w, apiErr.legacyResponse(content))
+	}
+}
+
+//Sign the unsigned tx of the given type with txSign and send it, see sendTx. Used by the legacy and the v1 endpoints.
+func sendUnsignedTx(req *http.Request, txHash [32]byte, txSign [64]byte, typeID uint8, quorum int) (results []*network.BroadcastResult, apiErr *ApiError) {
+	var err error
+
+	//A tx is not signed and sent twice concurrently. Other txs are checked and sent in parallel.
+	mutex.Lock()
+	if sendingTxs[txHash] {
+		mutex.Unlock()
+		return nil, &ApiError{Status: http.StatusConflict, Message: fmt.Sprintf("Transaction %x is already being sent", txHash[:8])}
+	}
+	sendingTxs[txHash] = true
+	mutex.Unlock()
+
+	defer func() {
+		mutex.Lock()
+		delete(sendingTxs, txHash)
+		mutex.Unlock()
+	}()
+
+	notFound := &ApiError{Status: http.StatusNotFound, Message: fmt.Sprintf("No transaction with hash %x found to sign", txHash)}
+
+	unsignedTx, signer := client.GetUnsignedTx(txHash, typeID)
+
+	switch typeID {
 	case p2p.ACCTX_BRDCST:
 		//logger.Print("ACCTX")
 
-		if tx := client.UnsignedAccTx[txHash]; tx != nil {
+		if tx, _ := unsignedTx.(*protocol.AccTx); tx != nil {
 			tx.Sig = txSign
-			err = network.SendTx(util.Config.BootstrapIpport, tx, p2p.ACCTX_BRDCST)
+			if apiErr := checkTx(req, tx, signer); apiErr != nil {
+				return nil, apiErr
+			}
+			results, err = sendTx(req, quorum, tx, p2p.ACCTX_BRDCST)
 
-			//If tx was successful or not, delete it from map either way. A new tx creation is the only option to repeat.
-			delete(client.UnsignedFundsTx, txHash)
+			//If sending the tx was successful or not, delete it from the store either way. A new tx creation is the only
+			//option to repeat. Txs which fail a check are kept, so they can be sent with a corrected signature.
+			client.RemoveUnsignedTx(txHash)
 		} else {
-			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("No transaction with hash %x found to sign", txHash), nil})
-			return
+			return nil, notFound
 		}
 	case p2p.CONFIGTX_BRDCST:
 		logger.Print("CONFIGTX")
 
-		if tx := client.UnsignedConfigTx[txHash]; tx != nil {
+		if tx, _ := unsignedTx.(*protocol.ConfigTx); tx != nil {
 			tx.Sig = txSign
-			err = network.SendTx(util.Config.BootstrapIpport, tx, p2p.CONFIGTX_BRDCST)
+			if apiErr := checkTx(req, tx, signer); apiErr != nil {
+				return nil, apiErr
+			}
+			results, err = sendTx(req, quorum, tx, p2p.CONFIGTX_BRDCST)
 
-			//If tx was successful or not, delete it from map either way. A new tx creation is the only option to repeat.
-			delete(client.UnsignedFundsTx, txHash)
+			//If sending the tx was successful or not, delete it from the store either way. A new tx creation is the only
+			//option to repeat. Txs which fail a check are kept, so they can be sent with a corrected signature.
+			client.RemoveUnsignedTx(txHash)
 		} else {
-			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("No transaction with hash %x found to sign", txHash), nil})
-			return
+			return nil, notFound
 		}
 	case p2p.FUNDSTX_BRDCST:
 		//logger.Print("FUNDSTX")
-		mutex.Lock()
-		if tx := client.UnsignedFundsTx[txHash]; tx != nil {
-			if tx.Sig == [64]byte{} {
-				tx.Sig = txSign
-				err = network.SendTx(util.Config.BootstrapIpport, tx, p2p.FUNDSTX_BRDCST)
-				if err != nil {
-					delete(client.UnsignedFundsTx, txHash)
-				}
+		if tx, _ := unsignedTx.(*protocol.FundsTx); tx != nil {
+			firstSig := tx.Sig == [64]byte{}
+			tx.Sig = txSign
+			if apiErr := checkTx(req, tx, signer); apiErr != nil {
+				return nil, apiErr
+			}
+			results, err = sendTx(req, quorum, tx, p2p.FUNDSTX_BRDCST)
+
+			if firstSig && err == nil {
+				client.UpdateUnsignedTx(tx)
 			} else {
-				tx.Sig = txSign
-				err = network.SendTx(util.Config.BootstrapIpport, tx, p2p.FUNDSTX_BRDCST)
-				delete(client.UnsignedFundsTx, txHash)
+				client.RemoveUnsignedTx(txHash)
 			}
 		} else {
 			//logger.Printf("No transaction with hash %x found to sign\n", txHash)
-			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("No transaction with hash %x found to sign", txHash), nil})
-			mutex.Unlock()
-
-			return
+			return nil, notFound
 		}
-		mutex.Unlock()
 
 	case p2p.IOTTX_BRDCST:
 		//logger.Print("IOTTX")
-		if tx := client.UnsignedIoTTx[txHash]; tx != nil {
-			if tx.Sig == [64]byte{} {
-				tx.Sig = txSign
-				err = network.SendTx(util.Config.MultisigIpport, tx, p2p.IOTTX_BRDCST)
-				if err != nil {
-					delete(client.UnsignedFundsTx, txHash)
-				}
+		if tx, _ := unsignedTx.(*protocol.IotTx); tx != nil {
+			firstSig := tx.Sig == [64]byte{}
+			tx.Sig = txSign
+			if apiErr := checkTx(req, tx, signer); apiErr != nil {
+				return nil, apiErr
+			}
+
+			if firstSig {
+				err = network.SendTx(req.Context(), util.Config.MultisigIpport, tx, p2p.IOTTX_BRDCST)
 			} else {
-				tx.Sig = txSign
-				err = network.SendTx(util.Config.BootstrapIpport, tx, p2p.IOTTX_BRDCST)
-				delete(client.UnsignedFundsTx, txHash)
+				results, err = sendTx(req, quorum, tx, p2p.IOTTX_BRDCST)
+			}
+
+			if firstSig && err == nil {
+				client.UpdateUnsignedTx(tx)
+			} else {
+				client.RemoveUnsignedTx(txHash)
 			}
 		} else {
 			//logger.Printf("No IoT transaction with hash %x found to sign\n", txHash)
-			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, fmt.Sprintf("No transaction with hash %x found to sign", txHash), nil})
-			return
+			return nil, notFound
 		}
+	default:
+		return nil, notFound
+	}
+
+	if err != nil {
+		return results, &ApiError{Status: http.StatusBadGateway, Message: err.Error()}
 	}
+
+	return results, nil
+}
+
+//Returns a 4xx error if the signed tx fails a check, see client.CheckTx. The tx must not be sent in this case.
+func checkTx(req *http.Request, tx protocol.Transaction, signer [32]byte) *ApiError {
+	err := client.CheckTx(req.Context(), tx, signer)
 	if err == nil {
-		SendJsonResponse(w, JsonResponse{http.StatusOK, fmt.Sprintf("Transaction %x successfully sent to network.", txHash[:8]), nil})
+		return nil
+	}
+
+	checkErr, ok := err.(*client.TxCheckError)
+	if !ok {
+		return networkError(err, "Checking the transaction failed")
+	}
+
+	status := http.StatusBadRequest
+	switch checkErr.Err {
+	case client.ErrUnknownAccount:
+		status = http.StatusNotFound
+	case client.ErrAccountExists:
+		status = http.StatusConflict
+	case client.ErrNotRoot:
+		status = http.StatusForbidden
+	}
+
+	return &ApiError{Status: status, Message: checkErr.Error(), Check: checkErr.Check, Reason: checkErr.Err.Error()}
+}
+
+//The optional quorum parameter asks for a broadcast to all connected miners, see network.BroadcastTx.
+func getQuorum(req *http.Request) (quorum int, err error) {
+	quorumParam := req.URL.Query().Get("quorum")
+	if len(quorumParam) == 0 {
+		return 0, nil
+	}
+
+	if quorum, err = strconv.Atoi(quorumParam); err != nil || quorum <= 0 {
+		return 0, errors.New(fmt.Sprintf("Invalid quorum %v", quorumParam))
+	}
+
+	return quorum, nil
+}
+
+//Without a quorum, the tx is sent to the bootstrap miner only and no broadcast results are returned. Sent txs are tracked
+//until they are confirmed, see GetTxStatusEndpoint.
+func sendTx(req *http.Request, quorum int, tx protocol.Transaction, typeID uint8) (results []*network.BroadcastResult, err error) {
+	if quorum > 0 {
+		results, err = network.BroadcastTx(req.Context(), tx, typeID, quorum)
 	} else {
-		//logger.Printf("Sending tx failed: %v\n", err.Error())
-		SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+		err = network.SendTx(req.Context(), util.Config.BootstrapIpport, tx, typeID)
+	}
+
+	if err == nil {
+		client.TrackTx(tx)
 	}
+
+	return results, err
 }
 
 func SendAccTxEndpoint(w http.ResponseWriter, req *http.Request) {
@@ -471,7 +588,12 @@ func SendIoTTxEndpoint(w http.ResponseWriter, req *http.Request) {
 	header, _ := strconv.Atoi(params["header"])
 
 	var iotData IoTData
-	var err error
+	quorum, err := getQuorum(req)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
 	if req.Body == nil {
 		http.Error(w, "Please send a request body", 400)
 		logger.Println("No Body...")
@@ -524,8 +646,6 @@ func SendIoTTxEndpoint(w http.ResponseWriter, req *http.Request) {
 
 
 
-
-	//Check the signature on the client side so that we cannot flood the network with already invalid transactions
 	toPub := [PUB_KEY_LEN]byte{}
 	for index := range iotData.To {
 		toPub[index] = byte(iotData.To[index])
@@ -540,6 +660,12 @@ func SendIoTTxEndpoint(w http.ResponseWriter, req *http.Request) {
 			Fee:  uint64(txFee),
 		}
 		txHash := IotTx.Hash()
+
+		//Check the signature on the client side so that we cannot flood the network with already invalid transactions
+		if apiErr := checkTx(req, &IotTx, fromPub); apiErr != nil {
+			SendJsonResponse(w, apiErr.legacyResponse(nil))
+			return
+		}
 		//IotTx.From = protocol.SerializeHashContent(fromPub);
 		//IotTx.To = protocol.SerializeHashContent(toPub)
 
@@ -548,16 +674,28 @@ func SendIoTTxEndpoint(w http.ResponseWriter, req *http.Request) {
 		//tx := client.SignedIotTx[txHash]
 		//mutex.Unlock()
 
-		err = network.SendIotTx(util.Config.BootstrapIpport, &IotTx, p2p.IOTTX_BRDCST)
+		var results []*network.BroadcastResult
+		if quorum > 0 {
+			results, err = network.BroadcastIotTx(req.Context(), &IotTx, p2p.IOTTX_BRDCST, quorum)
+		} else {
+			err = network.SendIotTx(req.Context(), util.Config.BootstrapIpport, &IotTx, p2p.IOTTX_BRDCST)
+		}
+
+		var broadcastContent []Content
+		for _, result := range results {
+			broadcastContent = append(broadcastContent, Content{"broadcast", result})
+		}
 
 		if err == nil {
-			SendJsonResponse(w, JsonResponse{http.StatusOK, fmt.Sprintf("Transaction %x successfully sent to network.", txHash[:8]), nil})
+			client.TrackTx(&IotTx)
+
+			SendJsonResponse(w, JsonResponse{http.StatusOK, fmt.Sprintf("Transaction %x successfully sent to network.", txHash[:8]), broadcastContent})
 
 			var content []Content
 			content = append(content, Content{"TxHash", hex.EncodeToString(txHash[:])})
 			SendJsonResponse(w, JsonResponse{http.StatusOK, "FundsTx successfully created.", content})
 		} else {
 			logger.Printf("Sending IotTx failed: %v\n", err.Error())
-			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), nil})
+			SendJsonResponse(w, JsonResponse{http.StatusInternalServerError, err.Error(), broadcastContent})
 		}
 }
@@ -0,0 +1,45 @@
+package REST
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+//Only txs sent through this REST service are tracked. The tx is confirmed once it has the number of confirmations
+//given by the confirmations parameter, util.TX_CONFIRMATIONS by default.
+func GetTxStatusEndpoint(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	hashBytes, err := hex.DecodeString(params["hash"])
+	if err != nil || len(hashBytes) != 32 {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, fmt.Sprintf("Invalid tx hash %v", params["hash"]), nil})
+		return
+	}
+
+	var txHash [32]byte
+	copy(txHash[:], hashBytes)
+
+	confirmations := uint64(util.TX_CONFIRMATIONS)
+	if confirmationsParam := req.URL.Query().Get("confirmations"); len(confirmationsParam) > 0 {
+		if confirmations, err = strconv.ParseUint(confirmationsParam, 10, 32); err != nil || confirmations == 0 {
+			SendJsonResponse(w, JsonResponse{http.StatusBadRequest, fmt.Sprintf("Invalid confirmations %v", confirmationsParam), nil})
+			return
+		}
+	}
+
+	status, tracked := client.GetTxStatus(txHash, uint32(confirmations))
+	if !tracked {
+		SendJsonResponse(w, JsonResponse{http.StatusNotFound, fmt.Sprintf("Tx %x is not tracked.", txHash[:8]), nil})
+		return
+	}
+
+	var content []Content
+	content = append(content, Content{"status", status})
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
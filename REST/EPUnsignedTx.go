@@ -0,0 +1,65 @@
+package REST
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+type UnsignedTxEntry struct {
+	Hash      string `json:"hash"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt"`
+	Fee       uint64 `json:"fee"`
+}
+
+var unsignedTxTypes = map[uint8]string{
+	p2p.ACCTX_BRDCST:    "acc",
+	p2p.CONFIGTX_BRDCST: "config",
+	p2p.FUNDSTX_BRDCST:  "funds",
+	p2p.IOTTX_BRDCST:    "iot",
+}
+
+//Lists the created txs which were neither sent nor removed and did not expire yet.
+func GetUnsignedTxsEndpoint(w http.ResponseWriter, req *http.Request) {
+	var content []Content
+	for _, unsignedTx := range client.GetUnsignedTxs() {
+		content = append(content, Content{"unsignedTx", UnsignedTxEntry{
+			Hash:      hex.EncodeToString(unsignedTx.Hash[:]),
+			Type:      unsignedTxTypes[unsignedTx.TypeID],
+			CreatedAt: unsignedTx.CreatedAt.UTC().Format(time.RFC3339),
+			ExpiresAt: unsignedTx.ExpiresAt.UTC().Format(time.RFC3339),
+			Fee:       unsignedTx.Tx.TxFee(),
+		}})
+	}
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
+
+//Cancels a created tx, so it cannot be sent anymore.
+func DeleteUnsignedTxEndpoint(w http.ResponseWriter, req *http.Request) {
+	params := mux.Vars(req)
+
+	hashBytes, err := hex.DecodeString(params["hash"])
+	if err != nil || len(hashBytes) != 32 {
+		SendJsonResponse(w, JsonResponse{http.StatusBadRequest, fmt.Sprintf("Invalid tx hash %v", params["hash"]), nil})
+		return
+	}
+
+	var txHash [32]byte
+	copy(txHash[:], hashBytes)
+
+	removed := client.RemoveUnsignedTx(txHash)
+
+	if !removed {
+		SendJsonResponse(w, JsonResponse{http.StatusNotFound, fmt.Sprintf("No transaction with hash %x found", txHash), nil})
+		return
+	}
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, fmt.Sprintf("Transaction %x removed.", txHash[:8]), nil})
+}
@@ -0,0 +1,31 @@
+package REST
+
+import (
+	"encoding/hex"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"net/http"
+)
+
+//The paths of the wallet files are not listed.
+type WalletEntry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+func GetWalletAccountsEndpoint(w http.ResponseWriter, req *http.Request) {
+	var content []Content
+	for _, account := range client.GetWalletAccounts() {
+		content = append(content, Content{"account", WalletEntry{account.Name, hex.EncodeToString(account.Address[:])}})
+	}
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
+
+func GetContactsEndpoint(w http.ResponseWriter, req *http.Request) {
+	var content []Content
+	for _, contact := range client.GetContacts() {
+		content = append(content, Content{"contact", WalletEntry{contact.Name, hex.EncodeToString(contact.Address[:])}})
+	}
+
+	SendJsonResponse(w, JsonResponse{http.StatusOK, "", content})
+}
@@ -0,0 +1,440 @@
+package REST
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//The v1 API takes JSON request bodies with hex encoded keys, hashes and signatures. It responds with the HTTP status
+//codes and reports errors as ErrorResponse. The legacy endpoints share the tx handling, see sendUnsignedTx.
+func getV1Endpoints(router *mux.Router) {
+	v1 := router.PathPrefix("/v1").Subrouter()
+	v1.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sendError(w, &ApiError{Status: http.StatusNotFound, Message: fmt.Sprintf("No endpoint %v %v", req.Method, req.URL.Path)})
+	})
+
+	v1.HandleFunc("/accounts/{address}", GetAccountV1Endpoint).Methods("GET")
+	v1.HandleFunc("/accounts/{address}/txs", GetAccountTxsV1Endpoint).Methods("GET")
+
+	v1.HandleFunc("/sync/status", GetSyncStatusV1Endpoint).Methods("GET")
+	v1.HandleFunc("/fee", GetFeeV1Endpoint).Methods("GET")
+	v1.HandleFunc("/network/parameters", GetNetworkParametersV1Endpoint).Methods("GET")
+
+	v1.HandleFunc("/wallet/accounts", GetWalletAccountsV1Endpoint).Methods("GET")
+	v1.HandleFunc("/wallet/contacts", GetContactsV1Endpoint).Methods("GET")
+
+	v1.HandleFunc("/txs/acc", CreateAccTxV1Endpoint).Methods("POST")
+	v1.HandleFunc("/txs/config", CreateConfigTxV1Endpoint).Methods("POST")
+	v1.HandleFunc("/txs/funds", CreateFundsTxV1Endpoint).Methods("POST")
+	v1.HandleFunc("/txs/{hash}/status", GetTxStatusV1Endpoint).Methods("GET")
+
+	v1.HandleFunc("/unsignedTxs", GetUnsignedTxsV1Endpoint).Methods("GET")
+	v1.HandleFunc("/unsignedTxs/{hash}", DeleteUnsignedTxV1Endpoint).Methods("DELETE")
+	v1.HandleFunc("/unsignedTxs/{hash}/send", SendUnsignedTxV1Endpoint).Methods("POST")
+}
+
+type AccTxRequest struct {
+	Header uint8  `json:"header"`
+	Fee    uint64 `json:"fee"`
+	PubKey string `json:"pubKey"`
+	Issuer string `json:"issuer"`
+}
+
+//Root is optional, see CreateConfigTxEndpoint.
+type ConfigTxRequest struct {
+	Header  uint8  `json:"header"`
+	Id      uint8  `json:"id"`
+	Payload uint64 `json:"payload"`
+	Fee     uint64 `json:"fee"`
+	TxCnt   uint8  `json:"txCnt"`
+	Root    string `json:"root"`
+}
+
+//Data is optional.
+type FundsTxRequest struct {
+	Header uint8  `json:"header"`
+	Amount uint64 `json:"amount"`
+	Fee    uint64 `json:"fee"`
+	TxCnt  uint32 `json:"txCnt"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Data   string `json:"data"`
+}
+
+type SendTxRequest struct {
+	Signature string `json:"signature"`
+	Quorum    int    `json:"quorum"`
+}
+
+type CreateTxResponse struct {
+	Hash string `json:"hash"`
+}
+
+type SendTxResponse struct {
+	Hash      string                     `json:"hash"`
+	Broadcast []*network.BroadcastResult `json:"broadcast,omitempty"`
+}
+
+type AccountResponse struct {
+	Account *client.Account `json:"account"`
+}
+
+type AccountTxsResponse struct {
+	Txs        []*client.TxHistoryEntry `json:"txs"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+}
+
+type UnsignedTxsResponse struct {
+	UnsignedTxs []UnsignedTxEntry `json:"unsignedTxs"`
+}
+
+type WalletResponse struct {
+	Accounts []WalletEntry `json:"accounts,omitempty"`
+	Contacts []WalletEntry `json:"contacts,omitempty"`
+}
+
+func GetAccountV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	acc, _, apiErr := getAccountV1(req)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	sendJson(w, http.StatusOK, AccountResponse{acc})
+}
+
+func GetAccountTxsV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	limit := 0
+	if limitParam := req.URL.Query().Get("limit"); len(limitParam) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 {
+			sendError(w, invalidField("limit", "Invalid limit %v", limitParam))
+			return
+		}
+	}
+
+	cursor := req.URL.Query().Get("cursor")
+	if err := client.ValidateHistoryCursor(cursor); err != nil {
+		sendError(w, invalidField("cursor", "%v", err))
+		return
+	}
+
+	address, apiErr := decodeHash("address", mux.Vars(req)["address"])
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	page, nextCursor, err := client.GetAccountHistory(req.Context(), address, cursor, limit)
+	if err != nil {
+		sendError(w, accountError(err))
+		return
+	}
+
+	if page == nil {
+		page = []*client.TxHistoryEntry{}
+	}
+
+	sendJson(w, http.StatusOK, AccountTxsResponse{page, nextCursor})
+}
+
+//The address is the account's 64 character public key.
+func getAccountV1(req *http.Request) (*client.Account, []*client.TxHistoryEntry, *ApiError) {
+	address, apiErr := decodeHash("address", mux.Vars(req)["address"])
+	if apiErr != nil {
+		return nil, nil, apiErr
+	}
+
+	acc, history, err := client.GetAccount(req.Context(), address)
+	if err != nil {
+		return nil, nil, accountError(err)
+	}
+
+	return acc, history, nil
+}
+
+//Unknown accounts are reported as 404, failed network requests as 502 or 504, see networkError.
+func accountError(err error) *ApiError {
+	var notFound *client.AccountNotFoundError
+	if errors.As(err, &notFound) {
+		return &ApiError{Status: http.StatusNotFound, Message: err.Error()}
+	}
+
+	var stateErr *client.AccountStateError
+	if errors.As(err, &stateErr) {
+		return networkError(stateErr.Err, "Could not calculate state of account %x", stateErr.Address[:8])
+	}
+
+	return networkError(err, "Could not calculate state of account")
+}
+
+func GetSyncStatusV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	sendJson(w, http.StatusOK, client.GetSyncStatus())
+}
+
+func GetFeeV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	estimate, err := client.EstimateFee(req.Context())
+	if err != nil {
+		sendError(w, &ApiError{Status: http.StatusBadGateway, Message: fmt.Sprintf("Fee estimation failed: %v", err)})
+		return
+	}
+
+	sendJson(w, http.StatusOK, estimate)
+}
+
+func GetNetworkParametersV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	parameters, err := client.GetNetworkParameters(req.Context())
+	if err != nil {
+		sendError(w, &ApiError{Status: http.StatusBadGateway, Message: fmt.Sprintf("Could not compute the network parameters: %v", err)})
+		return
+	}
+
+	sendJson(w, http.StatusOK, parameters)
+}
+
+func GetWalletAccountsV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	accounts := []WalletEntry{}
+	for _, account := range client.GetWalletAccounts() {
+		accounts = append(accounts, WalletEntry{account.Name, hex.EncodeToString(account.Address[:])})
+	}
+
+	sendJson(w, http.StatusOK, WalletResponse{Accounts: accounts})
+}
+
+func GetContactsV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	contacts := []WalletEntry{}
+	for _, contact := range client.GetContacts() {
+		contacts = append(contacts, WalletEntry{contact.Name, hex.EncodeToString(contact.Address[:])})
+	}
+
+	sendJson(w, http.StatusOK, WalletResponse{Contacts: contacts})
+}
+
+func CreateAccTxV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	var body AccTxRequest
+	if apiErr := decodeBody(req, &body); apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	pubKey, apiErr := decodeHash("pubKey", body.PubKey)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	issuer, apiErr := decodeHash("issuer", body.Issuer)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	tx := protocol.AccTx{
+		Header: body.Header,
+		Fee:    body.Fee,
+		PubKey: pubKey,
+		Issuer: issuer,
+	}
+
+	createTxV1(w, &tx, p2p.ACCTX_BRDCST, issuer)
+}
+
+func CreateConfigTxV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	var body ConfigTxRequest
+	if apiErr := decodeBody(req, &body); apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	//ConfigTxs do not name their issuer, their signature is verified against the root's public key.
+	if len(body.Root) == 0 {
+		sendError(w, invalidField("root", "The root's public key is required to verify the ConfigTx"))
+		return
+	}
+
+	root, apiErr := decodeHash("root", body.Root)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	tx := protocol.ConfigTx{
+		Header:  body.Header,
+		Id:      body.Id,
+		Payload: body.Payload,
+		Fee:     body.Fee,
+		TxCnt:   body.TxCnt,
+	}
+
+	createTxV1(w, &tx, p2p.CONFIGTX_BRDCST, root)
+}
+
+func CreateFundsTxV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	var body FundsTxRequest
+	if apiErr := decodeBody(req, &body); apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	from, apiErr := decodeHash("from", body.From)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	to, apiErr := decodeHash("to", body.To)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	data, err := hex.DecodeString(body.Data)
+	if err != nil {
+		sendError(w, invalidField("data", "Invalid data %v, expected hex characters", body.Data))
+		return
+	}
+
+	if body.Amount == 0 {
+		sendError(w, invalidField("amount", "Invalid amount 0, the amount must be > 0"))
+		return
+	}
+
+	tx := protocol.FundsTx{
+		Header: body.Header,
+		Amount: body.Amount,
+		Fee:    body.Fee,
+		TxCnt:  body.TxCnt,
+		From:   protocol.SerializeHashContent(from),
+		To:     protocol.SerializeHashContent(to),
+		Data:   data,
+	}
+
+	createTxV1(w, &tx, p2p.FUNDSTX_BRDCST, from)
+}
+
+func createTxV1(w http.ResponseWriter, tx protocol.Transaction, typeID uint8, signer [32]byte) {
+	txHash, err := client.AddUnsignedTx(tx, typeID, signer)
+	if err != nil {
+		sendError(w, &ApiError{Status: http.StatusInternalServerError, Message: err.Error()})
+		return
+	}
+
+	sendJson(w, http.StatusCreated, CreateTxResponse{hex.EncodeToString(txHash[:])})
+}
+
+//See GetTxStatusEndpoint.
+func GetTxStatusV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	txHash, apiErr := decodeHash("hash", mux.Vars(req)["hash"])
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	confirmations := uint64(util.TX_CONFIRMATIONS)
+	if confirmationsParam := req.URL.Query().Get("confirmations"); len(confirmationsParam) > 0 {
+		var err error
+		if confirmations, err = strconv.ParseUint(confirmationsParam, 10, 32); err != nil || confirmations == 0 {
+			sendError(w, invalidField("confirmations", "Invalid confirmations %v", confirmationsParam))
+			return
+		}
+	}
+
+	status, tracked := client.GetTxStatus(txHash, uint32(confirmations))
+	if !tracked {
+		sendError(w, &ApiError{Status: http.StatusNotFound, Message: fmt.Sprintf("Tx %x is not tracked.", txHash[:8])})
+		return
+	}
+
+	sendJson(w, http.StatusOK, status)
+}
+
+func GetUnsignedTxsV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	unsignedTxs := []UnsignedTxEntry{}
+	for _, unsignedTx := range client.GetUnsignedTxs() {
+		unsignedTxs = append(unsignedTxs, UnsignedTxEntry{
+			Hash:      hex.EncodeToString(unsignedTx.Hash[:]),
+			Type:      unsignedTxTypes[unsignedTx.TypeID],
+			CreatedAt: unsignedTx.CreatedAt.UTC().Format(time.RFC3339),
+			ExpiresAt: unsignedTx.ExpiresAt.UTC().Format(time.RFC3339),
+			Fee:       unsignedTx.Tx.TxFee(),
+		})
+	}
+
+	sendJson(w, http.StatusOK, UnsignedTxsResponse{unsignedTxs})
+}
+
+func DeleteUnsignedTxV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	txHash, apiErr := decodeHash("hash", mux.Vars(req)["hash"])
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	removed := client.RemoveUnsignedTx(txHash)
+
+	if !removed {
+		sendError(w, &ApiError{Status: http.StatusNotFound, Message: fmt.Sprintf("No transaction with hash %x found", txHash)})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//Signs the unsigned tx with the signature of the request and sends it. With a quorum, the tx is broadcast to all
+//connected miners, see network.BroadcastTx.
+func SendUnsignedTxV1Endpoint(w http.ResponseWriter, req *http.Request) {
+	txHash, apiErr := decodeHash("hash", mux.Vars(req)["hash"])
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	var body SendTxRequest
+	if apiErr := decodeBody(req, &body); apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	signature, apiErr := decodeHexField("signature", body.Signature, 64)
+	if apiErr != nil {
+		sendError(w, apiErr)
+		return
+	}
+
+	if body.Quorum < 0 {
+		sendError(w, invalidField("quorum", "Invalid quorum %v", body.Quorum))
+		return
+	}
+
+	typeID, ok := client.GetUnsignedTxType(txHash)
+	if !ok {
+		sendError(w, &ApiError{Status: http.StatusNotFound, Message: fmt.Sprintf("No transaction with hash %x found to sign", txHash)})
+		return
+	}
+
+	var txSign [64]byte
+	copy(txSign[:], signature)
+
+	results, apiErr := sendUnsignedTx(req, txHash, txSign, typeID, body.Quorum)
+	if apiErr != nil {
+		//If the quorum was not met, the results name the miners which did not acknowledge the tx.
+		if len(results) > 0 {
+			apiErr.Details = results
+		}
+
+		sendError(w, apiErr)
+		return
+	}
+
+	sendJson(w, http.StatusOK, SendTxResponse{hex.EncodeToString(txHash[:]), results})
+}
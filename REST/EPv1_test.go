@@ -0,0 +1,91 @@
+package REST
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+)
+
+func TestAccountError(t *testing.T) {
+	address := [32]byte{1}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"unknown account", &client.AccountNotFoundError{Address: address}, http.StatusNotFound},
+		{"timeout", &client.AccountStateError{Address: address, Err: network.ErrTimeout}, http.StatusGatewayTimeout},
+		{"deadline", &client.AccountStateError{Address: address, Err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+		{"miner not found", &client.AccountStateError{Address: address, Err: network.ErrNotFound}, http.StatusBadGateway},
+		{"transport error", &client.AccountStateError{Address: address, Err: errors.New("connection reset")}, http.StatusBadGateway},
+		{"unwrapped error", errors.New("connection reset"), http.StatusBadGateway},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if apiErr := accountError(test.err); apiErr.Status != test.status {
+				t.Errorf("got status %v (%v), want %v", apiErr.Status, apiErr.Message, test.status)
+			}
+		})
+	}
+}
+
+func TestCreateAccTxEndpointIsGone(t *testing.T) {
+	logger = util.InitLogger()
+
+	recorder := httptest.NewRecorder()
+	CreateAccTxEndpoint(recorder, httptest.NewRequest("POST", "/createAccTx/0/1/00", nil))
+
+	if recorder.Code != http.StatusGone {
+		t.Errorf("got status %v, want %v", recorder.Code, http.StatusGone)
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || resp.Error == nil || resp.Error.Status != http.StatusGone {
+		t.Errorf("got body %s (%v)", recorder.Body.Bytes(), err)
+	}
+}
+
+func TestCreateConfigTxRequiresRoot(t *testing.T) {
+	logger = util.InitLogger()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing root", `{"id": 1, "payload": 10, "fee": 1}`},
+		{"empty root", `{"id": 1, "payload": 10, "fee": 1, "root": ""}`},
+		{"invalid root", `{"id": 1, "payload": 10, "fee": 1, "root": "ab"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			CreateConfigTxV1Endpoint(recorder, httptest.NewRequest("POST", "/v1/txs/config", strings.NewReader(test.body)))
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || recorder.Code != http.StatusBadRequest ||
+				resp.Error == nil || resp.Error.Field != "root" {
+				t.Errorf("got status %v and body %s (%v)", recorder.Code, recorder.Body.Bytes(), err)
+			}
+		})
+	}
+
+	//The legacy endpoint reports the status in the body.
+	recorder := httptest.NewRecorder()
+	CreateConfigTxEndpoint(recorder, httptest.NewRequest("POST", "/createConfigTx/0/1/10/1/0", nil))
+
+	var resp JsonResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || resp.Code != http.StatusBadRequest {
+		t.Errorf("legacy endpoint: got body %s (%v)", recorder.Body.Bytes(), err)
+	}
+}
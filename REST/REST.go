@@ -19,12 +19,29 @@ func Init() {
 	logger.Printf("%v\n\n", "Starting REST...")
 
 	router := mux.NewRouter()
+	getV1Endpoints(router)
 	getEndpoints(router)
 	log.Fatal(http.ListenAndServe(":"+util.Config.Thisclient.Port, handlers.CORS()(router)))
 }
 
+//The legacy endpoints, which take their arguments as path segments and always respond with status 200. They are kept
+//for existing clients, new clients use the v1 API.
 func getEndpoints(router *mux.Router) {
 	router.HandleFunc("/account/{id}", GetAccountEndpoint).Methods("GET")
+	router.HandleFunc("/account/{id}/txs", GetAccountTxsEndpoint).Methods("GET")
+
+	router.HandleFunc("/sync/status", GetSyncStatusEndpoint).Methods("GET")
+
+	router.HandleFunc("/tx/{hash}/status", GetTxStatusEndpoint).Methods("GET")
+
+	router.HandleFunc("/unsignedTx", GetUnsignedTxsEndpoint).Methods("GET")
+	router.HandleFunc("/unsignedTx/{hash}", DeleteUnsignedTxEndpoint).Methods("DELETE")
+
+	router.HandleFunc("/fee", GetFeeEndpoint).Methods("GET")
+	router.HandleFunc("/network/parameters", GetNetworkParametersEndpoint).Methods("GET")
+
+	router.HandleFunc("/wallet/accounts", GetWalletAccountsEndpoint).Methods("GET")
+	router.HandleFunc("/wallet/contacts", GetContactsEndpoint).Methods("GET")
 
 	router.HandleFunc("/createAccTx/{header}/{fee}/{issuer}", CreateAccTxEndpoint).Methods("POST")
 	router.HandleFunc("/createAccTx/{pubKey}/{header}/{fee}/{issuer}", CreateAccTxEndpointWithPubKey).Methods("POST")
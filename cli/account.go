@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/client"
 	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/crypto"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/urfave/cli"
@@ -16,15 +21,15 @@ var (
 		Value:	0,
 	}
 
-	feeFlag = cli.Uint64Flag {
+	feeFlag = cli.StringFlag {
 		Name: 	"fee",
-		Usage:	"specify the fee",
-		Value:	1,
+		Usage:	"specify the fee, or `auto` to estimate it from recent blocks",
+		Value:	"1",
 	}
 
 	rootkeyFlag = cli.StringFlag {
 		Name: 	"rootwallet",
-		Usage: 	"load root's public private key from `FILE`",
+		Usage: 	"load root's public private key from `FILE` or the named account",
 	}
 )
 
@@ -32,20 +37,69 @@ func GetAccountCommand(logger *log.Logger) cli.Command {
 	return cli.Command {
 		Name:	"account",
 		Usage:	"account management",
+		Before:	requireNetwork,
 		Subcommands: []cli.Command {
 			getCheckAccountCommand(logger),
 			getCreateAccountCommand(logger),
 			getAddAccountCommand(logger),
+			getAccountHistoryCommand(logger),
 		},
 	}
 }
 
 
 
+//Load the address either from the hex string or the name of a contact or wallet account or, if not given, from the
+//wallet file.
+func loadAddress(addressString string, walletFile string) (address [32]byte, err error) {
+	if len(addressString) > 0 {
+		if address, ok := client.LookupAddress(addressString); ok {
+			return address, nil
+		}
+
+		return parseAddress(addressString)
+	} else if address, ok := client.LookupAddress(walletFile); ok {
+		return address, nil
+	} else if util.IsKeystoreFile(walletFile) {
+		pubKey, err := loadPubKey(walletFile)
+		if err != nil {
+			return address, err
+		}
+
+		copy(address[:], pubKey)
+	} else {
+		privKey, err := crypto.ExtractEDPrivKeyFromFile(walletFile)
+		if err != nil {
+			return address, err
+		}
+
+		copy(address[:], privKey[32:])
+	}
+
+	return address, nil
+}
+
+//Addresses are the hex encoded 32 byte public keys, i.e. 64 characters. Of the 128 character addresses accepted by
+//earlier versions, only the first 32 bytes are used, as before.
+func parseAddress(addressString string) (address [32]byte, err error) {
+	if len(addressString) != 64 && len(addressString) != 128 {
+		return address, errors.New("invalid argument: address must be 64 hex characters or a name")
+	}
+
+	addressBytes, err := hex.DecodeString(addressString)
+	if err != nil {
+		return address, errors.New("invalid argument: address must be 64 hex characters or a name")
+	}
+
+	copy(address[:], addressBytes)
+
+	return address, nil
+}
+
 func sendAccountTx(tx protocol.Transaction, logger *log.Logger) error {
 	//fmt.Printf("chash: %x\n", tx.Hash())
 
-	if err := network.SendTx(util.Config.BootstrapIpport, tx, p2p.ACCTX_BRDCST); err != nil {
+	if err := network.SendTx(context.Background(), util.Config.BootstrapIpport, tx, p2p.ACCTX_BRDCST); err != nil {
 		//logger.Printf("%v\n", err)
 		return err
 	} else {
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"errors"
-	"github.com/bazo-blockchain/bazo-miner/crypto"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/urfave/cli"
 	"log"
@@ -20,9 +19,14 @@ func getAddAccountCommand(logger *log.Logger) cli.Command {
 		Name: "add",
 			Usage: "add an existing account",
 			Action: func(c *cli.Context) error {
+			fee, err := parseFee(c.String("fee"), logger)
+			if err != nil {
+				return err
+			}
+
 			args := &addAccountArgs {
 				header: 		c.Int("header"),
-				fee: 			c.Uint64("fee"),
+				fee: 			fee,
 				rootWalletFile: c.String("rootwallet"),
 				address: 		c.String("address"),
 			}
@@ -35,7 +39,7 @@ func getAddAccountCommand(logger *log.Logger) cli.Command {
 			rootkeyFlag,
 			cli.StringFlag {
 				Name: 	"address",
-				Usage: 	"the account's address",
+				Usage: 	"the account's 64 character address or the name of a contact",
 			},
 		},
 	}
@@ -47,13 +51,15 @@ func addAccount(args *addAccountArgs, logger *log.Logger) error {
 		return err
 	}
 
-	privKey, err := crypto.ExtractEDPrivKeyFromFile(args.rootWalletFile)
+	privKey, err := loadPrivKey(args.rootWalletFile)
 	if err != nil {
 		return err
 	}
 
-	var newAddress [32]byte
-	copy(newAddress[:], privKey[32:])
+	newAddress, err := loadAddress(args.address, "")
+	if err != nil {
+		return err
+	}
 
 	tx, _, err := protocol.ConstrAccTx(byte(args.header), uint64(args.fee), newAddress, privKey, nil, nil)
 	if err != nil {
@@ -76,9 +82,5 @@ func (args addAccountArgs) ValidateInput() error {
 		return errors.New("argument missing: address")
 	}
 
-	if len(args.address) != 128 {
-		return errors.New("invalid argument length: address")
-	}
-
 	return nil
 }
\ No newline at end of file
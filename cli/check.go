@@ -1,12 +1,11 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"github.com/bazo-blockchain/bazo-client/client"
-	"github.com/bazo-blockchain/bazo-miner/crypto"
 	"github.com/urfave/cli"
 	"log"
-	"math/big"
 )
 
 type checkAccountArgs struct {
@@ -29,11 +28,11 @@ func getCheckAccountCommand(logger *log.Logger) cli.Command {
 		Flags: []cli.Flag {
 			cli.StringFlag {
 				Name: 	"address",
-				Usage: 	"the account's 128 byte address",
+				Usage: 	"the account's 64 character address or the name of a contact or account",
 			},
 			cli.StringFlag {
 				Name: 	"wallet",
-				Usage: 	"load the account's 128 byte address from `FILE`",
+				Usage: 	"load the account's address from `FILE` or the named account",
 				Value: 	"wallet.txt",
 			},
 		},
@@ -46,23 +45,15 @@ func checkAccount(args *checkAccountArgs, logger *log.Logger) error {
 		return err
 	}
 
-	var address [32]byte
-	if len(args.address) == 128 {
-		newPubInt, _ := new(big.Int).SetString(args.address, 16)
-		copy(address[:], newPubInt.Bytes())
-	} else {
-		privKey, err := crypto.ExtractEDPrivKeyFromFile(args.walletFile)
-		if err != nil {
-			logger.Printf("%v\n", err)
-			return err
-		}
-
-		copy(address[:], privKey[32:])
+	address, err := loadAddress(args.address, args.walletFile)
+	if err != nil {
+		logger.Printf("%v\n", err)
+		return err
 	}
 
 	logger.Printf("My address: %x\n", address)
 
-	acc, _, err := client.CheckAccount(address)
+	acc, _, err := client.CheckAccount(context.Background(), address)
 	if err != nil {
 		logger.Println(err)
 		return err
@@ -74,13 +65,10 @@ func checkAccount(args *checkAccountArgs, logger *log.Logger) error {
 }
 
 func (args checkAccountArgs) ValidateInput() error {
+	//The address is parsed by loadAddress, since it may be a name.
 	if len(args.address) == 0 && len(args.walletFile) == 0 {
 		return errors.New("argument missing: address or wallet")
 	}
-	//TODO: @ilecipi: check if 32 is correct
-	if len(args.walletFile) == 0 && len(args.address) != 32 {
-		return errors.New("invalid argument: address")
-	}
 
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/urfave/cli"
+	"sync"
+)
+
+var networkOnce sync.Once
+
+//Set as Before of the commands which talk to the miners. Offline commands like tx sign, keystore and wallet never dial
+//the bootstrap miner.
+func requireNetwork(c *cli.Context) (err error) {
+	networkOnce.Do(func() {
+		err = network.Init()
+	})
+
+	return err
+}
@@ -4,9 +4,10 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/bazo-blockchain/bazo-miner/crypto"
+	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/urfave/cli"
+	"golang.org/x/crypto/ed25519"
 	"log"
 	"os"
 )
@@ -16,6 +17,9 @@ type createAccountArgs struct {
 	fee				uint64
 	rootWalletFile	string
 	walletFile		string
+	plaintext		bool
+	index			int
+	derive			bool
 }
 
 func getCreateAccountCommand(logger *log.Logger) cli.Command {
@@ -23,11 +27,19 @@ func getCreateAccountCommand(logger *log.Logger) cli.Command {
 		Name: "create",
 		Usage: "create a new account and add it to the network",
 		Action: func(c *cli.Context) error {
+			fee, err := parseFee(c.String("fee"), logger)
+			if err != nil {
+				return err
+			}
+
 			args := &createAccountArgs {
 				header: 		c.Int("header"),
-				fee: 			c.Uint64("fee"),
+				fee: 			fee,
 				rootWalletFile: c.String("rootwallet"),
 				walletFile: 	c.String("wallet"),
+				plaintext:		c.Bool("plaintext"),
+				index:			c.Int("index"),
+				derive:			c.IsSet("index"),
 			}
 
 			return createAccount(args, logger)
@@ -40,6 +52,14 @@ func getCreateAccountCommand(logger *log.Logger) cli.Command {
 				Name: 	"wallet",
 				Usage: 	"save new account's public private key to `FILE`",
 			},
+			cli.BoolFlag {
+				Name: 	"plaintext",
+				Usage: 	"save the new account's keys unencrypted, e.g. for a miner",
+			},
+			cli.IntFlag {
+				Name: 	"index",
+				Usage: 	"use the `N`th key of the HD wallet instead of a random key",
+			},
 		},
 	}
 }
@@ -50,31 +70,75 @@ func createAccount(args *createAccountArgs, logger *log.Logger) error {
 		return err
 	}
 
-	privKey, err := crypto.ExtractEDPrivKeyFromFile(args.rootWalletFile)
+	privKey, err := loadPrivKey(args.rootWalletFile)
 	if err != nil {
 		return err
 	}
 
-	//Write the public key to the given textfile
-	file, err := os.Create(args.walletFile)
+	//A random key is generated for the zero address.
+	var newAddress [32]byte
+	var newPrivKey ed25519.PrivateKey
+	var passphrase []byte
+	if args.derive {
+		if newPrivKey, passphrase, err = deriveKey(args.index); err != nil {
+			return err
+		}
+
+		copy(newAddress[:], newPrivKey[32:])
+	}
+
+	tx, randomPrivKey, err := protocol.ConstrAccTx(byte(args.header), uint64(args.fee), newAddress, privKey, nil, nil)
 	if err != nil {
 		return err
 	}
 
-	tx, newPrivKey, err := protocol.ConstrAccTx(byte(args.header), uint64(args.fee), [32]byte{}, privKey, nil, nil)
+	if !args.derive {
+		newPrivKey = randomPrivKey
+	}
+
+	if args.plaintext {
+		err = writePlaintextWallet(args.walletFile, newPrivKey)
+	} else {
+		err = writeKeystore(args.walletFile, newPrivKey, passphrase)
+	}
+
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to write key to file %v: %v", args.walletFile, err))
+	}
+
+	return sendAccountTx(tx, logger)
+}
+
+//The keys are written in the format read by crypto.ExtractEDPrivKeyFromFile. Only the owner can read the file.
+func writePlaintextWallet(walletFile string, privKey ed25519.PrivateKey) error {
+	file, err := os.OpenFile(walletFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	_, err = file.WriteString(hex.EncodeToString(newPrivKey[32:])+ "\n")
-	_, err = file.WriteString(hex.EncodeToString(newPrivKey[0:32])+ "\n")
-	_, err = file.WriteString(hex.EncodeToString(newPrivKey[32:64])+ "\n")
+	_, err = file.WriteString(hex.EncodeToString(privKey[32:]) + "\n" +
+		hex.EncodeToString(privKey[0:32]) + "\n" +
+		hex.EncodeToString(privKey[32:64]) + "\n")
 
+	return err
+}
+
+//A new passphrase is prompted for if none is given.
+func writeKeystore(walletFile string, privKey ed25519.PrivateKey, passphrase []byte) error {
+	if passphrase == nil {
+		var err error
+		if passphrase, err = readNewPassphrase(NEW_PASSPHRASE_ENV); err != nil {
+			return err
+		}
+	}
+
+	keystore, err := util.EncryptKey(privKey, passphrase)
 	if err != nil {
-		return errors.New(fmt.Sprintf("failed to write key to file %v", args.walletFile))
+		return err
 	}
 
-	return sendAccountTx(tx, logger)
+	return util.WriteKeystore(walletFile, keystore)
 }
 
 func (args createAccountArgs) ValidateInput() error {
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/urfave/cli"
+	"log"
+	"strconv"
+)
+
+func GetFeeCommand(logger *log.Logger) cli.Command {
+	return cli.Command {
+		Name:	"fee",
+		Usage:	"fee estimation",
+		Before:	requireNetwork,
+		Subcommands: []cli.Command {
+			{
+				Name: 	"estimate",
+				Usage: 	"estimate the fee from recent blocks and the network's minimum fee",
+				Action:	func(c *cli.Context) error {
+					estimate, err := estimateFee()
+					if err != nil {
+						return err
+					}
+
+					logger.Printf("Minimum fee: %v\n", estimate.Minimum)
+					logger.Printf("Median fee of %v txs in the last %v blocks: %v\n", estimate.Txs, estimate.Blocks, estimate.Median)
+					logger.Printf("Recommended fee: %v\n", estimate.Recommended)
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+//The chain is synced first, so the estimate covers the latest blocks.
+func estimateFee() (*client.FeeEstimate, error) {
+	client.LoadBlockHeaders()
+
+	if err := client.SyncToNetwork(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return client.EstimateFee(context.Background())
+}
+
+//The fee is either a number or auto, which uses the recommended fee of client.EstimateFee.
+func parseFee(value string, logger *log.Logger) (uint64, error) {
+	if value != "auto" {
+		fee, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, errors.New("invalid argument: fee must be a number or auto")
+		}
+
+		return fee, nil
+	}
+
+	estimate, err := estimateFee()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Printf("Using fee %v\n", estimate.Recommended)
+
+	return estimate.Recommended, nil
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/network"
@@ -22,13 +23,23 @@ type fundsArgs struct {
 	amount			uint64
 	fee				uint64
 	txcount		    int
+	autoTxcount		bool
+	broadcastQuorum	int
+	wait			int
+	waitTimeout		int
 }
 
 func GetFundsCommand(logger *log.Logger) cli.Command {
 	return cli.Command {
 		Name:	"funds",
 		Usage:	"send funds from one account to another",
+		Before:	requireNetwork,
 		Action:	func(c *cli.Context) error {
+			fee, err := parseFee(c.String("fee"), logger)
+			if err != nil {
+				return err
+			}
+
 			args := &fundsArgs{
 				header: 		c.Int("header"),
 				fromWalletFile: c.String("from"),
@@ -36,8 +47,12 @@ func GetFundsCommand(logger *log.Logger) cli.Command {
 				toAddress: 		c.String("toAddress"),
 				multisigFile: 	c.String("multisig"),
 				amount: 		c.Uint64("amount"),
-				fee: 			c.Uint64("fee"),
+				fee: 			fee,
 				txcount:		c.Int("txcount"),
+				autoTxcount:	!c.IsSet("txcount"),
+				broadcastQuorum:	c.Int("broadcast-quorum"),
+				wait:			c.Int("wait"),
+				waitTimeout:	c.Int("wait-timeout"),
 			}
 
 			return sendFunds(args, logger)
@@ -50,33 +65,39 @@ func GetFundsCommand(logger *log.Logger) cli.Command {
 			},
 			cli.StringFlag {
 				Name: 	"from",
-				Usage: 	"load the sender's private key from `FILE`",
+				Usage: 	"load the sender's private key from `FILE` or the named account",
 			},
 			cli.StringFlag {
 				Name: 	"to",
-				Usage: 	"load the recipient's public key from `FILE`",
+				Usage: 	"load the recipient's public key from `FILE` or the named contact or account",
 			},
 			cli.StringFlag {
 				Name: 	"toAddress",
-				Usage: 	"the recipient's 128 byze public address",
+				Usage: 	"the recipient's 64 character address",
 			},
 			cli.Uint64Flag {
 				Name: 	"amount",
 				Usage:	"specify the amount to send",
 			},
-			cli.Uint64Flag {
+			cli.StringFlag {
 				Name: 	"fee",
-				Usage:	"specify the fee",
-				Value: 	1,
+				Usage:	"specify the fee, or `auto` to estimate it from recent blocks",
+				Value: 	"1",
 			},
 			cli.IntFlag {
 				Name: 	"txcount",
-				Usage:	"the sender's current transaction counter",
+				Usage:	"override the sender's transaction counter, which is computed from the sender's state by default",
 			},
 			cli.StringFlag {
 				Name: 	"multisig",
 				Usage: 	"load multi-signature server’s private key from `FILE`",
 			},
+			cli.IntFlag {
+				Name: 	"broadcast-quorum",
+				Usage:	"send the transaction to all connected miners and require `N` of them to acknowledge it",
+			},
+			waitFlag,
+			waitTimeoutFlag,
 		},
 	}
 }
@@ -87,7 +108,7 @@ func sendFunds(args *fundsArgs, logger *log.Logger) error {
 		return err
 	}
 
-	fromPrivKey, err := crypto.ExtractEDPrivKeyFromFile(args.fromWalletFile)
+	fromPrivKey, err := loadPrivKey(args.fromWalletFile)
 	if err != nil {
 		return err
 	}
@@ -97,18 +118,15 @@ func sendFunds(args *fundsArgs, logger *log.Logger) error {
 		if len(args.toAddress) == 0 {
 			return errors.New(fmt.Sprintln("No recipient specified"))
 		} else {
-			//TODO @ilecipi: check len
-			if len(args.toAddress) != 32 {
-				return errors.New(fmt.Sprintln("Invalid recipient address"))
-			}
-
-			toPubKey, err = crypto.ExtractEDPublicKeyFromFile(args.toAddress)
+			address, err := loadAddress(args.toAddress, "")
 			if err != nil {
 				return err
 			}
+
+			toPubKey = address[:]
 		}
 	} else {
-		toPubKey, err = crypto.ExtractEDPublicKeyFromFile(args.toWalletFile)
+		toPubKey, err = loadPubKey(args.toWalletFile)
 		if err != nil {
 			return err
 		}
@@ -119,7 +137,7 @@ func sendFunds(args *fundsArgs, logger *log.Logger) error {
 	fmt.Println(multisigPrivKey)
 
 	if len(args.multisigFile) > 0 {
-		multisigPrivKey, err = crypto.ExtractEDPrivKeyFromFile(args.multisigFile)
+		multisigPrivKey, err = loadPrivKey(args.multisigFile)
 		if err != nil {
 			return err
 		}
@@ -130,27 +148,56 @@ func sendFunds(args *fundsArgs, logger *log.Logger) error {
 	copy(fromAddress[:], fromPrivKey[32:])
 	toAddress := crypto.GetAddressFromPubKeyED(toPubKey)
 
+	txcount := uint32(args.txcount)
+	if args.autoTxcount {
+		if txcount, err = reserveTxcount(fromAddress, logger); err != nil {
+			return err
+		}
+	}
+
 	tx, err := protocol.ConstrFundsTx(
 		byte(args.header),
 		uint64(args.amount),
 		uint64(args.fee),
-		uint32(args.txcount),
+		txcount,
 		protocol.SerializeHashContent(fromAddress),
 		protocol.SerializeHashContent(toAddress),
 		fromPrivKey,
 		nil)
 	if err != nil {
 		logger.Printf("%v\n", err)
+		releaseTxcount(args.autoTxcount, fromAddress, txcount)
 		return err
 	}
 
-	if err := network.SendTx(util.Config.BootstrapIpport, tx, p2p.FUNDSTX_BRDCST); err != nil {
+	if args.broadcastQuorum > 0 {
+		results, err := network.BroadcastTx(context.Background(), tx, p2p.FUNDSTX_BRDCST, args.broadcastQuorum)
+		for _, result := range results {
+			if len(result.Error) > 0 {
+				logger.Printf("Miner %v: %v\n", result.Ipport, result.Error)
+			} else {
+				logger.Printf("Miner %v: acknowledged\n", result.Ipport)
+			}
+		}
+
+		if err != nil {
+			if acknowledged(results) == 0 {
+				releaseTxcount(args.autoTxcount, fromAddress, txcount)
+			}
+			return err
+		}
+	} else if err := network.SendTx(context.Background(), util.Config.BootstrapIpport, tx, p2p.FUNDSTX_BRDCST); err != nil {
 		//logger.Printf("%v\n", err)
+		releaseTxcount(args.autoTxcount, fromAddress, txcount)
 		return err
 	} else {
 		//logger.Printf("Transaction successfully sent to network:\nTxHash: %x%v", tx.Hash(), tx)
 	}
 
+	if args.wait > 0 {
+		return waitForConfirmations(tx, args.wait, args.waitTimeout, logger)
+	}
+
 	return nil
 }
 
@@ -163,12 +210,20 @@ func (args fundsArgs) ValidateInput() error {
 		return errors.New("invalid argument: txcnt must be >= 0")
 	}
 
-	if len(args.toWalletFile) == 0 && len(args.toAddress) == 0 {
-		return errors.New("argument missing: to or toAddess")
+	if args.broadcastQuorum < 0 {
+		return errors.New("invalid argument: broadcast-quorum must be >= 0")
+	}
+
+	if args.wait < 0 {
+		return errors.New("invalid argument: wait must be >= 0")
 	}
 
-	if len(args.toWalletFile) == 0 && len(args.toAddress) != 128 {
-		return errors.New("invalid argument: toAddress")
+	if args.waitTimeout <= 0 {
+		return errors.New("invalid argument: wait-timeout must be > 0")
+	}
+
+	if len(args.toWalletFile) == 0 && len(args.toAddress) == 0 {
+		return errors.New("argument missing: to or toAddess")
 	}
 
 	if args.fee <= 0 {
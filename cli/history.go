@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/urfave/cli"
+	"log"
+)
+
+type accountHistoryArgs struct {
+	address		string
+	walletFile	string
+	cursor		string
+	limit		int
+}
+
+func getAccountHistoryCommand(logger *log.Logger) cli.Command {
+	return cli.Command {
+		Name: "history",
+		Usage: "list the account's transactions, block rewards and fees",
+		Action: func(c *cli.Context) error {
+			args := &accountHistoryArgs {
+				address:	c.String("address"),
+				walletFile:	c.String("wallet"),
+				cursor:		c.String("cursor"),
+				limit:		c.Int("limit"),
+			}
+
+			return accountHistory(args, logger)
+		},
+		Flags: []cli.Flag {
+			cli.StringFlag {
+				Name: 	"address",
+				Usage: 	"the account's 64 character address or the name of a contact or account",
+			},
+			cli.StringFlag {
+				Name: 	"wallet",
+				Usage: 	"load the account's address from `FILE` or the named account",
+				Value: 	"wallet.txt",
+			},
+			cli.StringFlag {
+				Name: 	"cursor",
+				Usage: 	"continue after the entry with this id",
+			},
+			cli.IntFlag {
+				Name: 	"limit",
+				Usage: 	"the maximum number of entries to list",
+				Value:	client.HISTORY_DEFAULT_LIMIT,
+			},
+		},
+	}
+}
+
+func accountHistory(args *accountHistoryArgs, logger *log.Logger) error {
+	err := args.ValidateInput()
+	if err != nil {
+		return err
+	}
+
+	address, err := loadAddress(args.address, args.walletFile)
+	if err != nil {
+		logger.Printf("%v\n", err)
+		return err
+	}
+
+	history, nextCursor, err := client.CheckAccountHistory(context.Background(), address, args.cursor, args.limit)
+	if err != nil {
+		logger.Println(err)
+		return err
+	}
+
+	for _, entry := range history {
+		logger.Println(entry.String())
+	}
+
+	if len(nextCursor) > 0 {
+		logger.Printf("Next cursor: %v\n", nextCursor)
+	}
+
+	return nil
+}
+
+func (args accountHistoryArgs) ValidateInput() error {
+	//The address is parsed by loadAddress, since it may be a name.
+	if len(args.address) == 0 && len(args.walletFile) == 0 {
+		return errors.New("argument missing: address or wallet")
+	}
+
+	if args.limit <= 0 {
+		return errors.New("invalid argument: limit must be > 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/crypto"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/term"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	PASSPHRASE_ENV     = "BAZO_PASSPHRASE"
+	NEW_PASSPHRASE_ENV = "BAZO_NEW_PASSPHRASE"
+)
+
+//Shared by all prompts, so several passphrases can be piped into one command.
+var stdin = bufio.NewReader(os.Stdin)
+
+func GetKeystoreCommand(logger *log.Logger) cli.Command {
+	walletFlag := cli.StringFlag {
+		Name: 	"wallet",
+		Usage: 	"the wallet `FILE`",
+	}
+
+	return cli.Command {
+		Name:	"keystore",
+		Usage:	"encrypt wallet files with a passphrase",
+		Subcommands: []cli.Command {
+			{
+				Name: 	"convert",
+				Usage: 	"encrypt a plaintext wallet file",
+				Action:	func(c *cli.Context) error {
+					return convertWallet(c.String("wallet"), c.String("out"), logger)
+				},
+				Flags: []cli.Flag {
+					walletFlag,
+					cli.StringFlag {
+						Name: 	"out",
+						Usage: 	"write the keystore to `FILE` instead of replacing the wallet file",
+					},
+				},
+			},
+			{
+				Name: 	"reencrypt",
+				Usage: 	"encrypt a keystore again with a fresh salt and the current scrypt parameters",
+				Action:	func(c *cli.Context) error {
+					return reencryptWallet(c.String("wallet"), false, logger)
+				},
+				Flags: []cli.Flag {
+					walletFlag,
+				},
+			},
+			{
+				Name: 	"passphrase",
+				Usage: 	"change the passphrase of a keystore",
+				Action:	func(c *cli.Context) error {
+					return reencryptWallet(c.String("wallet"), true, logger)
+				},
+				Flags: []cli.Flag {
+					walletFlag,
+				},
+			},
+		},
+	}
+}
+
+func convertWallet(walletFile string, outFile string, logger *log.Logger) error {
+	if len(walletFile) == 0 {
+		return errors.New("argument missing: wallet")
+	}
+
+	if util.IsKeystoreFile(walletFile) {
+		return errors.New(fmt.Sprintf("%v is encrypted already", walletFile))
+	}
+
+	privKey, err := crypto.ExtractEDPrivKeyFromFile(walletFile)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readNewPassphrase(NEW_PASSPHRASE_ENV)
+	if err != nil {
+		return err
+	}
+
+	keystore, err := util.EncryptKey(privKey, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if len(outFile) == 0 {
+		outFile = walletFile
+	}
+
+	if err := util.WriteKeystore(outFile, keystore); err != nil {
+		return err
+	}
+
+	logger.Printf("Wallet %v encrypted to %v\n", walletFile, outFile)
+
+	return nil
+}
+
+func reencryptWallet(walletFile string, changePassphrase bool, logger *log.Logger) error {
+	if len(walletFile) == 0 {
+		return errors.New("argument missing: wallet")
+	}
+
+	keystore, err := util.ReadKeystore(walletFile)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase of %v: ", walletFile), PASSPHRASE_ENV)
+	if err != nil {
+		return err
+	}
+
+	privKey, err := keystore.Decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+
+	if changePassphrase {
+		if passphrase, err = readNewPassphrase(NEW_PASSPHRASE_ENV); err != nil {
+			return err
+		}
+	}
+
+	if keystore, err = util.EncryptKey(privKey, passphrase); err != nil {
+		return err
+	}
+
+	if err := util.WriteKeystore(walletFile, keystore); err != nil {
+		return err
+	}
+
+	logger.Printf("Wallet %v encrypted again\n", walletFile)
+
+	return nil
+}
+
+//Load the private key from a keystore or a plaintext wallet file, which may be given by the name of a wallet account.
+//The passphrase of a keystore is prompted for.
+func loadPrivKey(walletFile string) (ed25519.PrivateKey, error) {
+	walletFile = resolveWalletFile(walletFile)
+
+	if !util.IsKeystoreFile(walletFile) {
+		return crypto.ExtractEDPrivKeyFromFile(walletFile)
+	}
+
+	keystore, err := util.ReadKeystore(walletFile)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase of %v: ", walletFile), PASSPHRASE_ENV)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.Decrypt(passphrase)
+}
+
+//Load the public key of a wallet account or contact with the given name, or else from a keystore or a plaintext wallet
+//file. No passphrase is needed.
+func loadPubKey(walletFile string) (ed25519.PublicKey, error) {
+	if address, ok := client.LookupAddress(walletFile); ok {
+		return ed25519.PublicKey(address[:]), nil
+	}
+
+	if !util.IsKeystoreFile(walletFile) {
+		return crypto.ExtractEDPublicKeyFromFile(walletFile)
+	}
+
+	keystore, err := util.ReadKeystore(walletFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.PubKey()
+}
+
+//The passphrase is taken from the environment variable if it is set, e.g. for scripts. Otherwise it is read from the
+//terminal without echoing it, or from stdin if it is not a terminal.
+func readPassphrase(prompt string, env string) ([]byte, error) {
+	if passphrase, set := os.LookupEnv(env); set {
+		return []byte(passphrase), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("reading the passphrase failed: %v", err))
+		}
+
+		return passphrase, nil
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return nil, errors.New("no passphrase given")
+	}
+
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
+
+func readNewPassphrase(env string) ([]byte, error) {
+	passphrase, err := readPassphrase("New passphrase: ", env)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("invalid argument: passphrase must not be empty")
+	}
+
+	if _, set := os.LookupEnv(env); set {
+		return passphrase, nil
+	}
+
+	repeated, err := readPassphrase("Repeat the new passphrase: ", env)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(passphrase, repeated) {
+		return nil, errors.New("the passphrases do not match")
+	}
+
+	return passphrase, nil
+}
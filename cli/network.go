@@ -1,10 +1,11 @@
 package cli
 
 import (
+	"context"
 	"errors"
+	"github.com/bazo-blockchain/bazo-client/client"
 	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-client/util"
-	"github.com/bazo-blockchain/bazo-miner/crypto"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/urfave/cli"
@@ -15,9 +16,12 @@ type networkArgs struct {
 	header      	int
 	fee         	uint64
 	txcount     	int
+	autoTxcount		bool
 	rootWalletFile 	string
 	optionId    	uint8
 	payload     	uint64
+	wait			int
+	waitTimeout		int
 }
 
 type configOption struct {
@@ -26,32 +30,51 @@ type configOption struct {
 	usage			string
 }
 
-func GetNetworkCommand(logger *log.Logger) cli.Command {
-	options := []configOption {
-		{ id: 1, name: "setBlockSize", usage: "set the size of blocks (in bytes)" },
-		{ id: 2, name: "setDifficultyInterval", usage: "set the difficulty interval (in number of blocks)" },
-		{ id: 3, name: "setMinimumFee", usage: "set the minimum fee (in Bazo coins)" },
-		{ id: 4, name: "setBlockInterval", usage: "set the block interval (in seconds)" },
-		{ id: 5, name: "setBlockReward", usage: "set the block reward (in Bazo coins)" },
-	}
+//The options of the network command and tx build config. Each option is sent as one ConfigTx.
+var configOptions = []configOption {
+	{ id: 1, name: "setBlockSize", usage: "set the size of blocks (in bytes)" },
+	{ id: 2, name: "setDifficultyInterval", usage: "set the difficulty interval (in number of blocks)" },
+	{ id: 3, name: "setMinimumFee", usage: "set the minimum fee (in Bazo coins)" },
+	{ id: 4, name: "setBlockInterval", usage: "set the block interval (in seconds)" },
+	{ id: 5, name: "setBlockReward", usage: "set the block reward (in Bazo coins)" },
+}
 
+func GetNetworkCommand(logger *log.Logger) cli.Command {
 	command := cli.Command {
 		Name:	"network",
 		Usage:	"configure the network",
+		Before:	requireNetwork,
+		Subcommands: []cli.Command {
+			{
+				Name: 	"show",
+				Usage: 	"show the network's parameters and the configuration transactions which changed them",
+				Action:	func(c *cli.Context) error {
+					return showNetwork(logger)
+				},
+			},
+		},
 		Action:	func(c *cli.Context) error {
+			fee, err := parseFee(c.String("fee"), logger)
+			if err != nil {
+				return err
+			}
+
 			optionsSetByUser := 0
-			for _, option := range options {
+			for _, option := range configOptions {
 				if !c.IsSet(option.name) { continue }
 
 				optionsSetByUser++
 
 				args := &networkArgs {
 					header:      	c.Int("header"),
-					fee:         	c.Uint64("fee"),
+					fee:         	fee,
 					rootWalletFile: c.String("rootwallet"),
 					optionId:    	option.id,
 					payload:     	c.Uint64(option.name),
 					txcount:		c.Int("txcount"),
+					autoTxcount:	!c.IsSet("txcount"),
+					wait:			c.Int("wait"),
+					waitTimeout:	c.Int("wait-timeout"),
 				}
 
 				err := configureNetwork(args, logger)
@@ -72,23 +95,25 @@ func GetNetworkCommand(logger *log.Logger) cli.Command {
 				Usage: 	"header flag",
 				Value:	0,
 			},
-			cli.Uint64Flag {
+			cli.StringFlag {
 				Name: 	"fee",
-				Usage:	"specify the fee",
-				Value: 	1,
+				Usage:	"specify the fee, or `auto` to estimate it from recent blocks",
+				Value: 	"1",
 			},
 			cli.IntFlag {
 				Name: 	"txcount",
-				Usage:	"the sender's current transaction counter",
+				Usage:	"override the root's transaction counter, which is computed from the root's state by default",
 			},
 			cli.StringFlag {
 				Name: 	"rootwallet",
-				Usage: 	"load root's public key from `FILE`",
+				Usage: 	"load root's public key from `FILE` or the named account",
 			},
+			waitFlag,
+			waitTimeoutFlag,
 		},
 	}
 
-	for _, option := range options {
+	for _, option := range configOptions {
 		flag := cli.Uint64Flag { Name: option.name, Usage: option.usage }
 		command.Flags = append(command.Flags, flag)
 	}
@@ -102,34 +127,83 @@ func configureNetwork(args *networkArgs, logger *log.Logger) error {
 		return err
 	}
 
-	privKey, err := crypto.ExtractEDPrivKeyFromFile(args.rootWalletFile)
+	privKey, err := loadPrivKey(args.rootWalletFile)
 	if err != nil {
 		return err
 	}
 
+	var rootAddress [32]byte
+	copy(rootAddress[:], privKey[32:])
+
+	txcount := uint32(args.txcount)
+	if args.autoTxcount {
+		if txcount, err = reserveTxcount(rootAddress, logger); err != nil {
+			return err
+		}
+	}
+
 	tx, err := protocol.ConstrConfigTx(
 		byte(args.header),
 		uint8(args.optionId),
 		uint64(args.payload),
 		uint64(args.fee),
-		uint8(args.txcount),
+		uint8(txcount),
 		privKey)
 
 	if err != nil {
+		releaseTxcount(args.autoTxcount, rootAddress, txcount)
 		return err
 	}
 
 	if tx == nil {
+		releaseTxcount(args.autoTxcount, rootAddress, txcount)
 		return errors.New("transaction encoding failed")
 	}
 
-	if err := network.SendTx(util.Config.BootstrapIpport, tx, p2p.CONFIGTX_BRDCST); err != nil {
+	if err := network.SendTx(context.Background(), util.Config.BootstrapIpport, tx, p2p.CONFIGTX_BRDCST); err != nil {
 		//logger.Printf("%v\n", err)
+		releaseTxcount(args.autoTxcount, rootAddress, txcount)
 		return err
 	} else {
 		//logger.Printf("Transaction successfully sent to network:\nTxHash: %x%v", tx.Hash(), tx)
 	}
 
+	if args.wait > 0 {
+		return waitForConfirmations(tx, args.wait, args.waitTimeout, logger)
+	}
+
+	return nil
+}
+
+func showNetwork(logger *log.Logger) error {
+	client.LoadBlockHeaders()
+
+	//Changes of the root operators are only shown once the headers of their blocks are synced.
+	if err := client.SyncToNetwork(context.Background()); err != nil {
+		return err
+	}
+
+	parameters, err := client.GetNetworkParameters(context.Background())
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("Network parameters at height %v:\n", parameters.Height)
+	logger.Printf("Block size: %v bytes\n", parameters.BlockSize)
+	logger.Printf("Difficulty interval: %v blocks\n", parameters.DiffInterval)
+	logger.Printf("Minimum fee: %v\n", parameters.FeeMinimum)
+	logger.Printf("Block interval: %v sec\n", parameters.BlockInterval)
+	logger.Printf("Block reward: %v\n", parameters.BlockReward)
+
+	for _, change := range parameters.History {
+		status := "applied"
+		if !change.Applied {
+			status = "rejected"
+		}
+
+		logger.Printf("Height %v: %v set to %v by tx %v (%v)\n", change.Height, change.Parameter, change.Payload, change.TxHash, status)
+	}
+
 	return nil
 }
 
@@ -146,5 +220,13 @@ func (args networkArgs) ValidateInput() error {
 		return errors.New("argument missing: rootwallet")
 	}
 
+	if args.wait < 0 {
+		return errors.New("invalid argument: wait must be >= 0")
+	}
+
+	if args.waitTimeout <= 0 {
+		return errors.New("invalid argument: wait-timeout must be > 0")
+	}
+
 	return nil
 }
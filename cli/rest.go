@@ -10,6 +10,7 @@ func GetRestCommand() cli.Command {
 	return cli.Command {
 		Name:	"rest",
 		Usage:	"start the REST service",
+		Before:	requireNetwork,
 		Action:	func(c *cli.Context) error {
 			client.Sync()
 			REST.Init()
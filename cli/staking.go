@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"github.com/bazo-blockchain/bazo-client/network"
@@ -18,6 +19,8 @@ type stakingArgs struct {
 	walletFile		string
 	commitment		string
 	stakingValue	bool
+	wait			int
+	waitTimeout		int
 }
 
 func GetStakingCommand(logger *log.Logger) cli.Command {
@@ -27,27 +30,31 @@ func GetStakingCommand(logger *log.Logger) cli.Command {
 		Value:	0,
 	}
 
-	feeFlag := cli.Uint64Flag {
+	feeFlag := cli.StringFlag {
 		Name: 	"fee",
-		Usage:	"specify the fee",
-		Value: 	1,
+		Usage:	"specify the fee, or `auto` to estimate it from recent blocks",
+		Value: 	"1",
 	}
 
 	walletFlag := cli.StringFlag {
 		Name: 	"wallet, w",
-		Usage: 	"load validator's public key from `FILE`",
+		Usage: 	"load validator's public key from `FILE` or the named account",
 		Value: 	"wallet.txt",
 	}
 
 	return cli.Command {
 		Name:	"staking",
 		Usage:	"enable or disable staking",
+		Before:	requireNetwork,
 		Subcommands: []cli.Command {
 			{
 				Name: "enable",
 				Usage: "join the pool of validators",
 				Action:	func(c *cli.Context) error {
-					args := parseStakingArgs(c)
+					args, err := parseStakingArgs(c, logger)
+					if err != nil {
+						return err
+					}
 					args.stakingValue = true
 					return toggleStaking(args, logger)
 				},
@@ -55,6 +62,8 @@ func GetStakingCommand(logger *log.Logger) cli.Command {
 					headerFlag,
 					feeFlag,
 					walletFlag,
+					waitFlag,
+					waitTimeoutFlag,
 					cli.StringFlag {
 						Name: 	"commitment",
 						Usage: 	"load valiadator's commitment key from `FILE`",
@@ -66,7 +75,10 @@ func GetStakingCommand(logger *log.Logger) cli.Command {
 				Name: "disable",
 				Usage: "leave the pool of validators",
 				Action:	func(c *cli.Context) error {
-					args := parseStakingArgs(c)
+					args, err := parseStakingArgs(c, logger)
+					if err != nil {
+						return err
+					}
 					args.stakingValue = false
 					return toggleStaking(args, logger)
 				},
@@ -74,19 +86,28 @@ func GetStakingCommand(logger *log.Logger) cli.Command {
 					headerFlag,
 					feeFlag,
 					walletFlag,
+					waitFlag,
+					waitTimeoutFlag,
 				},
 			},
 		},
 	}
 }
 
-func parseStakingArgs(c *cli.Context) *stakingArgs {
+func parseStakingArgs(c *cli.Context, logger *log.Logger) (*stakingArgs, error) {
+	fee, err := parseFee(c.String("fee"), logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &stakingArgs {
 		header: 			c.Int("header"),
-		fee: 				c.Uint64("fee"),
+		fee: 				fee,
 		walletFile:	 		c.String("wallet"),
 		commitment:			c.String("commitment"),
-	}
+		wait:				c.Int("wait"),
+		waitTimeout:		c.Int("wait-timeout"),
+	}, nil
 }
 
 func toggleStaking(args *stakingArgs, logger *log.Logger) error {
@@ -95,7 +116,7 @@ func toggleStaking(args *stakingArgs, logger *log.Logger) error {
 		return err
 	}
 
-	privKey, err := crypto.ExtractEDPrivKeyFromFile(args.walletFile)
+	privKey, err := loadPrivKey(args.walletFile)
 	if err != nil {
 		return err
 	}
@@ -128,13 +149,17 @@ func toggleStaking(args *stakingArgs, logger *log.Logger) error {
 		return errors.New("transaction encoding failed")
 	}
 
-	if err := network.SendTx(util.Config.BootstrapIpport, tx, p2p.STAKETX_BRDCST); err != nil {
+	if err := network.SendTx(context.Background(), util.Config.BootstrapIpport, tx, p2p.STAKETX_BRDCST); err != nil {
 		//logger.Printf("%v\n", err)
 		return err
 	} else {
 		//logger.Printf("Transaction successfully sent to network:\nTxHash: %x%v", tx.Hash(), tx)
 	}
 
+	if args.wait > 0 {
+		return waitForConfirmations(tx, args.wait, args.waitTimeout, logger)
+	}
+
 	return nil
 }
 
@@ -151,5 +176,13 @@ func (args stakingArgs) ValidateInput() error {
 		return errors.New("argument missing: commitment")
 	}
 
+	if args.wait < 0 {
+		return errors.New("invalid argument: wait must be >= 0")
+	}
+
+	if args.waitTimeout <= 0 {
+		return errors.New("invalid argument: wait-timeout must be > 0")
+	}
+
 	return nil
 }
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/urfave/cli"
+	"log"
+	"time"
+)
+
+func GetSyncCommand(logger *log.Logger) cli.Command {
+	return cli.Command {
+		Name:	"sync",
+		Usage:	"sync the block headers with the network",
+		Before:	requireNetwork,
+		Action:	func(c *cli.Context) error {
+			if c.Bool("verify") {
+				return verifyBlockHeaders(logger)
+			}
+
+			return syncBlockHeaders(logger)
+		},
+		Flags: []cli.Flag {
+			cli.BoolFlag {
+				Name: 	"verify",
+				Usage: 	"validate the stored block headers instead of syncing",
+			},
+		},
+	}
+}
+
+func syncBlockHeaders(logger *log.Logger) error {
+	client.LoadBlockHeaders()
+
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(util.SYNC_PROGRESS_INTERVAL * time.Second):
+				if status := client.GetSyncStatus(); status.Syncing {
+					logger.Printf("Sync %v: %v/%v headers, height %v of %v, ETA %v\n",
+						status.Phase, status.Done, status.Total, status.Height, status.TargetHeight,
+						time.Duration(status.EtaSeconds)*time.Second)
+				}
+			}
+		}
+	}()
+
+	err := client.SyncToNetwork(context.Background())
+	close(done)
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("Synced to height %v\n", client.GetSyncStatus().Height)
+
+	return nil
+}
+
+func verifyBlockHeaders(logger *log.Logger) error {
+	verified, err := client.VerifyBlockHeaders(context.Background())
+	if err != nil {
+		logger.Printf("%v headers valid before: %v\n", verified, err)
+		return err
+	}
+
+	logger.Printf("All %v stored headers are valid\n", verified)
+
+	return nil
+}
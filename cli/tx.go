@@ -0,0 +1,634 @@
+package cli
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/crypto"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ed25519"
+	"io/ioutil"
+	"log"
+)
+
+const (
+	TX_TYPE_FUNDS  = "funds"
+	TX_TYPE_CONFIG = "config"
+	TX_TYPE_STAKE  = "stake"
+	TX_TYPE_ACC    = "acc"
+)
+
+//A tx file as written by tx build and tx sign. The tx is encoded like it is sent to the miners. The signer is the address
+//whose key must sign the tx.
+type txFile struct {
+	Type   string `json:"type"`
+	Hash   string `json:"hash"`
+	Signer string `json:"signer"`
+	Tx     string `json:"tx"`
+}
+
+func GetTxCommand(logger *log.Logger) cli.Command {
+	outFlag := cli.StringFlag {
+		Name: 	"out",
+		Usage: 	"write the unsigned transaction to `FILE`",
+	}
+
+	fileFlag := cli.StringFlag {
+		Name: 	"file",
+		Usage: 	"the transaction `FILE`",
+	}
+
+	feeFlag := cli.StringFlag {
+		Name: 	"fee",
+		Usage:	"specify the fee, or `auto` to estimate it from recent blocks",
+		Value: 	"1",
+	}
+
+	txcountFlag := cli.IntFlag {
+		Name: 	"txcount",
+		Usage:	"override the signer's transaction counter, which is computed from the signer's state by default",
+	}
+
+	configFlags := []cli.Flag {
+		headerFlag,
+		feeFlag,
+		txcountFlag,
+		outFlag,
+		cli.StringFlag {
+			Name: 	"root",
+			Usage: 	"the root's address, name or wallet `FILE`",
+		},
+	}
+	for _, option := range configOptions {
+		configFlags = append(configFlags, cli.Uint64Flag { Name: option.name, Usage: option.usage })
+	}
+
+	return cli.Command {
+		Name:	"tx",
+		Usage:	"build, sign and submit transactions in separate steps, e.g. to sign them offline",
+		Subcommands: []cli.Command {
+			{
+				Name: 	"build",
+				Usage: 	"build an unsigned transaction, only the signer's address is needed",
+				Before:	requireNetwork,
+				Subcommands: []cli.Command {
+					{
+						Name: 	"funds",
+						Usage: 	"build a transfer of funds",
+						Action:	func(c *cli.Context) error {
+							return buildTx(c, logger, buildFundsTx)
+						},
+						Flags: []cli.Flag {
+							headerFlag,
+							feeFlag,
+							txcountFlag,
+							outFlag,
+							cli.StringFlag {
+								Name: 	"from",
+								Usage: 	"the sender's address, name or wallet `FILE`",
+							},
+							cli.StringFlag {
+								Name: 	"to",
+								Usage: 	"the recipient's address, name or wallet `FILE`",
+							},
+							cli.Uint64Flag {
+								Name: 	"amount",
+								Usage:	"specify the amount to send",
+							},
+						},
+					},
+					{
+						Name: 	"config",
+						Usage: 	"build a configuration transaction",
+						Action:	func(c *cli.Context) error {
+							return buildTx(c, logger, buildConfigTx)
+						},
+						Flags: configFlags,
+					},
+					{
+						Name: 	"stake",
+						Usage: 	"build a transaction which enables or disables staking",
+						Action:	func(c *cli.Context) error {
+							return buildTx(c, logger, buildStakeTx)
+						},
+						Flags: []cli.Flag {
+							headerFlag,
+							feeFlag,
+							outFlag,
+							cli.StringFlag {
+								Name: 	"account",
+								Usage: 	"the validator's address, name or wallet `FILE`",
+							},
+							cli.StringFlag {
+								Name: 	"commitment",
+								Usage: 	"load the validator's commitment key from `FILE` to enable staking",
+							},
+							cli.BoolFlag {
+								Name: 	"disable",
+								Usage: 	"disable staking instead",
+							},
+						},
+					},
+					{
+						Name: 	"account",
+						Usage: 	"build a transaction which adds an account to the network",
+						Action:	func(c *cli.Context) error {
+							return buildTx(c, logger, buildAccTx)
+						},
+						Flags: []cli.Flag {
+							headerFlag,
+							feeFlag,
+							outFlag,
+							cli.StringFlag {
+								Name: 	"root",
+								Usage: 	"the root's address, name or wallet `FILE`",
+							},
+							cli.StringFlag {
+								Name: 	"address",
+								Usage: 	"the new account's address, name or wallet `FILE`",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: 	"sign",
+				Usage: 	"sign a transaction file, no network access is needed",
+				Action:	func(c *cli.Context) error {
+					return signTxFile(c.String("file"), c.String("wallet"), c.String("out"), logger)
+				},
+				Flags: []cli.Flag {
+					fileFlag,
+					cli.StringFlag {
+						Name: 	"wallet",
+						Usage: 	"load the signer's private key from `FILE` or the named account",
+					},
+					cli.StringFlag {
+						Name: 	"out",
+						Usage: 	"write the signed transaction to `FILE` instead of replacing the transaction file",
+					},
+				},
+			},
+			{
+				Name: 	"discard",
+				Usage: 	"release the transaction counter reserved by tx build for a transaction which is not submitted",
+				Action:	func(c *cli.Context) error {
+					return discardTxFile(c.String("file"), logger)
+				},
+				Flags: []cli.Flag {
+					fileFlag,
+				},
+			},
+			{
+				Name: 	"submit",
+				Usage: 	"send a signed transaction file to the network",
+				Before:	requireNetwork,
+				Action:	func(c *cli.Context) error {
+					return submitTxFile(c.String("file"), c.Int("broadcast-quorum"), c.Int("wait"), c.Int("wait-timeout"), logger)
+				},
+				Flags: []cli.Flag {
+					fileFlag,
+					cli.IntFlag {
+						Name: 	"broadcast-quorum",
+						Usage:	"send the transaction to all connected miners and require `N` of them to acknowledge it",
+					},
+					waitFlag,
+					waitTimeoutFlag,
+				},
+			},
+		},
+	}
+}
+
+//Builds the unsigned tx from the command's flags and returns it together with its type and the signer's address.
+type txBuilder func(c *cli.Context, fee uint64, logger *log.Logger) (tx protocol.Transaction, txType string, signer [32]byte, err error)
+
+func buildTx(c *cli.Context, logger *log.Logger, build txBuilder) error {
+	if len(c.String("out")) == 0 {
+		return errors.New("argument missing: out")
+	}
+
+	fee, err := parseFee(c.String("fee"), logger)
+	if err != nil {
+		return err
+	}
+
+	if fee <= 0 {
+		return errors.New("invalid argument: fee must be > 0")
+	}
+
+	tx, txType, signer, err := build(c, fee, logger)
+	if err != nil {
+		return err
+	}
+
+	if err := writeTxFile(c.String("out"), tx, txType, signer); err != nil {
+		return err
+	}
+
+	txHash := tx.Hash()
+	logger.Printf("Unsigned tx %x written to %v\n", txHash[:8], c.String("out"))
+
+	return nil
+}
+
+func buildFundsTx(c *cli.Context, fee uint64, logger *log.Logger) (protocol.Transaction, string, [32]byte, error) {
+	from, err := loadAddress(c.String("from"), c.String("from"))
+	if err != nil {
+		return nil, "", from, err
+	}
+
+	to, err := loadAddress(c.String("to"), c.String("to"))
+	if err != nil {
+		return nil, "", from, err
+	}
+
+	if c.Uint64("amount") <= 0 {
+		return nil, "", from, errors.New("invalid argument: amount must be > 0")
+	}
+
+	txcount, err := buildTxcount(c, from, logger)
+	if err != nil {
+		return nil, "", from, err
+	}
+
+	tx := &protocol.FundsTx{
+		Header: byte(c.Int("header")),
+		Amount: c.Uint64("amount"),
+		Fee:    fee,
+		TxCnt:  txcount,
+		From:   protocol.SerializeHashContent(from),
+		To:     protocol.SerializeHashContent(to),
+		Data:   []byte{},
+	}
+
+	return tx, TX_TYPE_FUNDS, from, nil
+}
+
+func buildConfigTx(c *cli.Context, fee uint64, logger *log.Logger) (protocol.Transaction, string, [32]byte, error) {
+	root, err := loadAddress(c.String("root"), c.String("root"))
+	if err != nil {
+		return nil, "", root, err
+	}
+
+	var selected []configOption
+	for _, option := range configOptions {
+		if c.IsSet(option.name) {
+			selected = append(selected, option)
+		}
+	}
+
+	if len(selected) != 1 {
+		return nil, "", root, errors.New("specify exactly one configuration option per transaction")
+	}
+
+	txcount, err := buildTxcount(c, root, logger)
+	if err != nil {
+		return nil, "", root, err
+	}
+
+	tx := &protocol.ConfigTx{
+		Header:  byte(c.Int("header")),
+		Id:      selected[0].id,
+		Payload: c.Uint64(selected[0].name),
+		Fee:     fee,
+		TxCnt:   uint8(txcount),
+	}
+
+	return tx, TX_TYPE_CONFIG, root, nil
+}
+
+func buildStakeTx(c *cli.Context, fee uint64, logger *log.Logger) (protocol.Transaction, string, [32]byte, error) {
+	account, err := loadAddress(c.String("account"), c.String("account"))
+	if err != nil {
+		return nil, "", account, err
+	}
+
+	isStaking := !c.Bool("disable")
+	if isStaking && len(c.String("commitment")) == 0 {
+		return nil, "", account, errors.New("argument missing: commitment")
+	}
+
+	commPubKey := &rsa.PublicKey{}
+	if isStaking {
+		commPrivKey, err := crypto.ExtractRSAKeyFromFile(c.String("commitment"))
+		if err != nil {
+			return nil, "", account, err
+		}
+		commPubKey = &commPrivKey.PublicKey
+	}
+
+	//The commitment key is encoded by protocol.ConstrStakeTx. Its signature with a throwaway key is removed, the tx is
+	//signed by tx sign.
+	_, throwawayKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, "", account, err
+	}
+
+	tx, err := protocol.ConstrStakeTx(byte(c.Int("header")), fee, isStaking, protocol.SerializeHashContent(account[:]), throwawayKey, commPubKey)
+	if err != nil {
+		return nil, "", account, err
+	}
+
+	if tx == nil {
+		return nil, "", account, errors.New("transaction encoding failed")
+	}
+
+	tx.Sig = [64]byte{}
+
+	return tx, TX_TYPE_STAKE, account, nil
+}
+
+func buildAccTx(c *cli.Context, fee uint64, logger *log.Logger) (protocol.Transaction, string, [32]byte, error) {
+	root, err := loadAddress(c.String("root"), c.String("root"))
+	if err != nil {
+		return nil, "", root, err
+	}
+
+	address, err := loadAddress(c.String("address"), c.String("address"))
+	if err != nil {
+		return nil, "", root, err
+	}
+
+	tx := &protocol.AccTx{
+		Header: byte(c.Int("header")),
+		Issuer: protocol.SerializeHashContent(root),
+		Fee:    fee,
+		PubKey: address,
+	}
+
+	return tx, TX_TYPE_ACC, root, nil
+}
+
+//The TxCnt is computed like for funds, unless it is passed with --txcount.
+func buildTxcount(c *cli.Context, address [32]byte, logger *log.Logger) (uint32, error) {
+	if c.IsSet("txcount") {
+		if c.Int("txcount") < 0 {
+			return 0, errors.New("invalid argument: txcnt must be >= 0")
+		}
+
+		return uint32(c.Int("txcount")), nil
+	}
+
+	return reserveTxcount(address, logger)
+}
+
+func signTxFile(file string, walletFile string, outFile string, logger *log.Logger) error {
+	if len(file) == 0 {
+		return errors.New("argument missing: file")
+	}
+
+	if len(walletFile) == 0 {
+		return errors.New("argument missing: wallet")
+	}
+
+	tx, txType, signer, err := readTxFile(file)
+	if err != nil {
+		return err
+	}
+
+	//The content is shown as decoded from the tx, so it can be checked before signing.
+	logger.Printf("Signing %v tx: %v\n", txType, describeTx(tx))
+
+	privKey, err := loadPrivKey(walletFile)
+	if err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(privKey[32:]) != hex.EncodeToString(signer[:]) {
+		return errors.New(fmt.Sprintf("the tx must be signed by %x", signer))
+	}
+
+	txHash := tx.Hash()
+	var sig [64]byte
+	copy(sig[:], ed25519.Sign(privKey, txHash[:]))
+
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		tx.Sig = sig
+	case *protocol.ConfigTx:
+		tx.Sig = sig
+	case *protocol.StakeTx:
+		tx.Sig = sig
+	case *protocol.AccTx:
+		tx.Sig = sig
+	}
+
+	if len(outFile) == 0 {
+		outFile = file
+	}
+
+	if err := writeTxFile(outFile, tx, txType, signer); err != nil {
+		return err
+	}
+
+	logger.Printf("Signed tx %x written to %v\n", txHash[:8], outFile)
+
+	return nil
+}
+
+func submitTxFile(file string, broadcastQuorum int, wait int, waitTimeout int, logger *log.Logger) error {
+	if len(file) == 0 {
+		return errors.New("argument missing: file")
+	}
+
+	if broadcastQuorum < 0 {
+		return errors.New("invalid argument: broadcast-quorum must be >= 0")
+	}
+
+	if wait < 0 {
+		return errors.New("invalid argument: wait must be >= 0")
+	}
+
+	if waitTimeout <= 0 {
+		return errors.New("invalid argument: wait-timeout must be > 0")
+	}
+
+	tx, txType, signer, err := readTxFile(file)
+	if err != nil {
+		return err
+	}
+
+	sig := client.TxSig(tx)
+	if sig == [64]byte{} {
+		return errors.New(fmt.Sprintf("the tx is not signed yet, sign it with the key of %x", signer))
+	}
+
+	txHash := tx.Hash()
+	if !ed25519.Verify(signer[:], txHash[:], sig[:]) {
+		return errors.New(fmt.Sprintf("the tx is not signed by %x", signer))
+	}
+
+	typeID := map[string]uint8{
+		TX_TYPE_FUNDS:  p2p.FUNDSTX_BRDCST,
+		TX_TYPE_CONFIG: p2p.CONFIGTX_BRDCST,
+		TX_TYPE_STAKE:  p2p.STAKETX_BRDCST,
+		TX_TYPE_ACC:    p2p.ACCTX_BRDCST,
+	}[txType]
+
+	//The TxCnt reserved by tx build is handed out again if the tx did not reach any miner.
+	txcount, hasTxcount := txFileTxcount(tx)
+
+	if broadcastQuorum > 0 {
+		results, err := network.BroadcastTx(context.Background(), tx, typeID, broadcastQuorum)
+		for _, result := range results {
+			if len(result.Error) > 0 {
+				logger.Printf("Miner %v: %v\n", result.Ipport, result.Error)
+			} else {
+				logger.Printf("Miner %v: acknowledged\n", result.Ipport)
+			}
+		}
+
+		if err != nil {
+			releaseTxcount(hasTxcount && acknowledged(results) == 0, signer, txcount)
+			return err
+		}
+	} else if err := network.SendTx(context.Background(), util.Config.BootstrapIpport, tx, typeID); err != nil {
+		releaseTxcount(hasTxcount, signer, txcount)
+		return err
+	}
+
+	logger.Printf("Tx %x sent\n", txHash[:8])
+
+	if wait > 0 {
+		return waitForConfirmations(tx, wait, waitTimeout, logger)
+	}
+
+	return nil
+}
+
+//Transactions which are built but never submitted keep their TxCnt reserved until the reservation expires, unless
+//they are discarded.
+func discardTxFile(file string, logger *log.Logger) error {
+	if len(file) == 0 {
+		return errors.New("argument missing: file")
+	}
+
+	tx, _, signer, err := readTxFile(file)
+	if err != nil {
+		return err
+	}
+
+	txcount, hasTxcount := txFileTxcount(tx)
+	if !hasTxcount {
+		return errors.New("the tx has no transaction counter")
+	}
+
+	releaseTxcount(true, signer, txcount)
+
+	txHash := tx.Hash()
+	logger.Printf("Txcount %v of tx %x released, %v can be deleted\n", txcount, txHash[:8], file)
+
+	return nil
+}
+
+//Only funds and config txs have a TxCnt, see buildTxcount.
+func txFileTxcount(tx protocol.Transaction) (uint32, bool) {
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		return tx.TxCnt, true
+	case *protocol.ConfigTx:
+		return uint32(tx.TxCnt), true
+	}
+
+	return 0, false
+}
+
+func writeTxFile(file string, tx protocol.Transaction, txType string, signer [32]byte) error {
+	txHash := tx.Hash()
+	content, err := json.MarshalIndent(txFile{
+		Type:   txType,
+		Hash:   hex.EncodeToString(txHash[:]),
+		Signer: hex.EncodeToString(signer[:]),
+		Tx:     hex.EncodeToString(tx.Encode()),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(content, '\n'), 0600)
+}
+
+//The tx is decoded and its hash is checked against the one written by tx build.
+func readTxFile(file string) (tx protocol.Transaction, txType string, signer [32]byte, err error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, "", signer, err
+	}
+
+	var parsed txFile
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: %v", file, err))
+	}
+
+	encoded, err := hex.DecodeString(parsed.Tx)
+	if err != nil {
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: tx", file))
+	}
+
+	signerBytes, err := hex.DecodeString(parsed.Signer)
+	if err != nil || len(signerBytes) != 32 {
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: signer", file))
+	}
+	copy(signer[:], signerBytes)
+
+	switch parsed.Type {
+	case TX_TYPE_FUNDS:
+		var fundsTx *protocol.FundsTx
+		if fundsTx = fundsTx.Decode(encoded); fundsTx != nil {
+			tx = fundsTx
+		}
+	case TX_TYPE_CONFIG:
+		var configTx *protocol.ConfigTx
+		if configTx = configTx.Decode(encoded); configTx != nil {
+			tx = configTx
+		}
+	case TX_TYPE_STAKE:
+		var stakeTx *protocol.StakeTx
+		if stakeTx = stakeTx.Decode(encoded); stakeTx != nil {
+			tx = stakeTx
+		}
+	case TX_TYPE_ACC:
+		var accTx *protocol.AccTx
+		if accTx = accTx.Decode(encoded); accTx != nil {
+			tx = accTx
+		}
+	default:
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: unknown type %v", file, parsed.Type))
+	}
+
+	if tx == nil {
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: decoding the tx failed", file))
+	}
+
+	if txHash := tx.Hash(); hex.EncodeToString(txHash[:]) != parsed.Hash {
+		return nil, "", signer, errors.New(fmt.Sprintf("invalid tx file %v: the hash does not match the tx", file))
+	}
+
+	return tx, parsed.Type, signer, nil
+}
+
+func describeTx(tx protocol.Transaction) string {
+	txHash := tx.Hash()
+
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		return fmt.Sprintf("Hash: %x, From: %x, To: %x, Amount: %v, Fee: %v, TxCnt: %v", txHash[:8], tx.From[:8], tx.To[:8], tx.Amount, tx.Fee, tx.TxCnt)
+	case *protocol.ConfigTx:
+		return fmt.Sprintf("Hash: %x, Id: %v, Payload: %v, Fee: %v, TxCnt: %v", txHash[:8], tx.Id, tx.Payload, tx.Fee, tx.TxCnt)
+	case *protocol.StakeTx:
+		return fmt.Sprintf("Hash: %x, Account: %x, IsStaking: %v, Fee: %v", txHash[:8], tx.Account[:8], tx.IsStaking, tx.Fee)
+	case *protocol.AccTx:
+		return fmt.Sprintf("Hash: %x, Issuer: %x, Address: %x, Fee: %v", txHash[:8], tx.Issuer[:8], tx.PubKey, tx.Fee)
+	}
+
+	return fmt.Sprintf("Hash: %x", txHash[:8])
+}
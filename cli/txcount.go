@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"log"
+)
+
+//Compute the next TxCnt of the account from the chain and its pending txs, see client.ReserveTxCnt. The chain is synced
+//first, so txs of blocks after the stored headers are counted.
+func reserveTxcount(address [32]byte, logger *log.Logger) (uint32, error) {
+	client.LoadBlockHeaders()
+
+	if err := client.SyncToNetwork(context.Background()); err != nil {
+		return 0, err
+	}
+
+	txcount, err := client.ReserveTxCnt(context.Background(), address)
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Printf("Using txcount %v\n", txcount)
+
+	return txcount, nil
+}
+
+//Hand the TxCnt out again if the tx did not reach any miner. Does nothing if the TxCnt was passed by the user.
+func releaseTxcount(reserved bool, address [32]byte, txcount uint32) {
+	if reserved {
+		client.ReleaseTxCnt(address, txcount)
+	}
+}
+
+func acknowledged(results []*network.BroadcastResult) (acks int) {
+	for _, result := range results {
+		if len(result.Error) == 0 {
+			acks++
+		}
+	}
+
+	return acks
+}
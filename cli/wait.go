@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"github.com/urfave/cli"
+	"log"
+	"sync"
+	"time"
+)
+
+//The network command waits for each of its transactions, the headers are synced only once.
+var syncOnce sync.Once
+
+var waitFlag = cli.IntFlag {
+	Name: 	"wait",
+	Usage:	"wait until the transaction is included in a block followed by `N`-1 blocks",
+}
+
+var waitTimeoutFlag = cli.IntFlag {
+	Name: 	"wait-timeout",
+	Usage:	"stop waiting after `SECONDS`, the transaction may still be confirmed later",
+	Value: 	util.TX_WAIT_TIMEOUT,
+}
+
+//Sync with the network and block until the sent tx has the given number of confirmations or the timeout in seconds
+//passed.
+func waitForConfirmations(tx protocol.Transaction, confirmations int, timeout int, logger *log.Logger) error {
+	syncOnce.Do(client.Sync)
+	client.TrackTx(tx)
+
+	txHash := tx.Hash()
+	logger.Printf("Waiting for %v confirmations of tx %x\n", confirmations, txHash[:8])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	status, err := client.WaitForTx(ctx, txHash, uint32(confirmations))
+	if err == context.DeadlineExceeded {
+		if status != nil && status.Status == client.TX_STATUS_INCLUDED {
+			return errors.New(fmt.Sprintf("tx %x still pending after %v seconds: included in block %v with %v of %v confirmations", txHash[:8], timeout, status.BlockHash, status.Confirmations, confirmations))
+		}
+
+		return errors.New(fmt.Sprintf("tx %x still pending after %v seconds: not included in a block yet", txHash[:8], timeout))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("Tx %x confirmed in block %v with height %v\n", txHash[:8], status.BlockHash, status.Height)
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/client"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ed25519"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+func GetWalletCommand(logger *log.Logger) cli.Command {
+	nameFlag := cli.StringFlag {
+		Name: 	"name",
+		Usage: 	"the `NAME` used in place of the wallet file or address",
+	}
+
+	return cli.Command {
+		Name:	"wallet",
+		Usage:	"named accounts and contacts",
+		Subcommands: []cli.Command {
+			{
+				Name: 	"add",
+				Usage: 	"add a wallet file as named account",
+				Action:	func(c *cli.Context) error {
+					return addWalletAccount(c.String("name"), c.String("wallet"), logger)
+				},
+				Flags: []cli.Flag {
+					nameFlag,
+					cli.StringFlag {
+						Name: 	"wallet",
+						Usage: 	"the account's wallet `FILE`",
+					},
+				},
+			},
+			{
+				Name: 	"remove",
+				Usage: 	"remove a named account, the wallet file is kept",
+				Action:	func(c *cli.Context) error {
+					return client.RemoveWalletAccount(c.String("name"))
+				},
+				Flags: []cli.Flag {
+					nameFlag,
+				},
+			},
+			{
+				Name: 	"list",
+				Usage: 	"list the named accounts and contacts",
+				Action:	func(c *cli.Context) error {
+					listWallet(logger)
+					return nil
+				},
+			},
+			{
+				Name: 	"init",
+				Usage: 	"initialize the HD wallet with a new or an existing mnemonic",
+				Action:	func(c *cli.Context) error {
+					return initHDWallet(c.String("mnemonic"), c.Bool("force"), logger)
+				},
+				Flags: []cli.Flag {
+					cli.StringFlag {
+						Name: 	"mnemonic",
+						Usage: 	"recover the HD wallet from the 24 `WORDS`, a new mnemonic is generated if omitted",
+					},
+					cli.BoolFlag {
+						Name: 	"force",
+						Usage: 	"replace an existing HD wallet",
+					},
+				},
+			},
+			{
+				Name: 	"derive",
+				Usage: 	"derive the key with the given index from the HD wallet and save it to a wallet file",
+				Action:	func(c *cli.Context) error {
+					return deriveWallet(c.Int("index"), c.String("wallet"), c.String("name"), c.Bool("plaintext"), logger)
+				},
+				Flags: []cli.Flag {
+					nameFlag,
+					cli.IntFlag {
+						Name: 	"index",
+						Usage: 	"the `N`th key of the HD wallet",
+					},
+					cli.StringFlag {
+						Name: 	"wallet",
+						Usage: 	"save the derived key to `FILE`",
+					},
+					cli.BoolFlag {
+						Name: 	"plaintext",
+						Usage: 	"save the derived key unencrypted, e.g. for a miner",
+					},
+				},
+			},
+			{
+				Name: 	"contact",
+				Usage: 	"manage the address book",
+				Subcommands: []cli.Command {
+					{
+						Name: 	"add",
+						Usage: 	"add a named contact",
+						Action:	func(c *cli.Context) error {
+							return addContact(c.String("name"), c.String("address"), c.String("wallet"), logger)
+						},
+						Flags: []cli.Flag {
+							nameFlag,
+							cli.StringFlag {
+								Name: 	"address",
+								Usage: 	"the contact's 64 character address",
+							},
+							cli.StringFlag {
+								Name: 	"wallet",
+								Usage: 	"load the contact's address from `FILE`",
+							},
+						},
+					},
+					{
+						Name: 	"remove",
+						Usage: 	"remove a named contact",
+						Action:	func(c *cli.Context) error {
+							return client.RemoveContact(c.String("name"))
+						},
+						Flags: []cli.Flag {
+							nameFlag,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func addWalletAccount(name string, walletFile string, logger *log.Logger) error {
+	if len(walletFile) == 0 {
+		return errors.New("argument missing: wallet")
+	}
+
+	//The account is used from any working directory.
+	walletFile, err := filepath.Abs(walletFile)
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := loadPubKey(walletFile)
+	if err != nil {
+		return err
+	}
+
+	var address [32]byte
+	copy(address[:], pubKey)
+
+	if err := client.AddWalletAccount(name, address, walletFile); err != nil {
+		return err
+	}
+
+	logger.Printf("Account %v added: %x\n", name, address)
+
+	return nil
+}
+
+func addContact(name string, addressString string, walletFile string, logger *log.Logger) error {
+	if len(addressString) == 0 && len(walletFile) == 0 {
+		return errors.New("argument missing: address or wallet")
+	}
+
+	address, err := loadAddress(addressString, walletFile)
+	if err != nil {
+		return err
+	}
+
+	if err := client.AddContact(name, address); err != nil {
+		return err
+	}
+
+	logger.Printf("Contact %v added: %x\n", name, address)
+
+	return nil
+}
+
+func initHDWallet(mnemonic string, force bool, logger *log.Logger) error {
+	if client.HasHDWallet() && !force {
+		return errors.New("HD wallet initialized already, pass --force to replace it")
+	}
+
+	generated := len(mnemonic) == 0
+	if generated {
+		var err error
+		if mnemonic, err = client.NewMnemonic(); err != nil {
+			return err
+		}
+	}
+
+	passphrase, err := readNewPassphrase(NEW_PASSPHRASE_ENV)
+	if err != nil {
+		return err
+	}
+
+	if err := client.InitHDWallet(strings.Join(strings.Fields(mnemonic), " "), passphrase); err != nil {
+		return err
+	}
+
+	if generated {
+		logger.Printf("Write down the mnemonic, it recovers every key derived from the HD wallet:\n%v\n", mnemonic)
+	}
+
+	logger.Println("HD wallet initialized")
+
+	return nil
+}
+
+//The derived key is encrypted with the passphrase of the HD wallet.
+func deriveWallet(index int, walletFile string, name string, plaintext bool, logger *log.Logger) error {
+	if len(walletFile) == 0 {
+		return errors.New("argument missing: wallet")
+	}
+
+	privKey, passphrase, err := deriveKey(index)
+	if err != nil {
+		return err
+	}
+
+	if plaintext {
+		err = writePlaintextWallet(walletFile, privKey)
+	} else {
+		err = writeKeystore(walletFile, privKey, passphrase)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("Key %v derived: %x\n", index, privKey[32:])
+
+	if len(name) > 0 {
+		return addWalletAccount(name, walletFile, logger)
+	}
+
+	return nil
+}
+
+//Returns the derived key together with the passphrase of the HD wallet.
+func deriveKey(index int) (ed25519.PrivateKey, []byte, error) {
+	if index < 0 {
+		return nil, nil, errors.New("invalid argument: index must be >= 0")
+	}
+
+	passphrase, err := readPassphrase("Passphrase of the HD wallet: ", PASSPHRASE_ENV)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privKey, err := client.DeriveKey(uint32(index), passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privKey, passphrase, nil
+}
+
+func listWallet(logger *log.Logger) {
+	for _, account := range client.GetWalletAccounts() {
+		logger.Printf("Account %v: %x (%v)\n", account.Name, account.Address, account.File)
+	}
+
+	for _, contact := range client.GetContacts() {
+		logger.Printf("Contact %v: %x\n", contact.Name, contact.Address)
+	}
+}
+
+//Wallet arguments are either the name of a wallet account or the path of a wallet file.
+func resolveWalletFile(nameOrFile string) string {
+	if account := client.GetWalletAccount(nameOrFile); account != nil {
+		return account.File
+	}
+
+	return nameOrFile
+}
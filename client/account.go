@@ -1,76 +1,175 @@
 package client
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/network"
-	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+	"time"
 )
 
+//No accTx exists for the initial root account, its creation fields remain empty.
 type Account struct {
-	Address       [32]byte `json:"-"`
-	AddressString string   `json:"address"`
-	Balance       uint64   `json:"balance"`
-	TxCnt         uint32   `json:"txCnt"`
-	IsCreated     bool     `json:"isCreated"`
-	IsRoot        bool     `json:"isRoot"`
-	IsStaking     bool     `json:"isStaking"`
+	Address         [32]byte `json:"-"`
+	AddressString   string   `json:"address"`
+	Balance         uint64   `json:"balance"`
+	TxCnt           uint32   `json:"txCnt"`
+	IsCreated       bool     `json:"isCreated"`
+	IsRoot          bool     `json:"isRoot"`
+	IsStaking       bool     `json:"isStaking"`
+	CreatedAtHeight uint32   `json:"createdAtHeight"`
+	CreatedInBlock  string   `json:"createdInBlock,omitempty"`
+	Issuer          string   `json:"issuer,omitempty"`
 }
 
-func CheckAccount(address [32]byte) (*Account, []*FundsTxJson, error) {
-	loadBlockHeaders()
-	return GetAccount(address)
+//Returned by GetAccount if the network does not know the account.
+type AccountNotFoundError struct {
+	Address [32]byte
 }
 
-func GetAccount(address [32]byte) (*Account, []*FundsTxJson, error) {
+func (e *AccountNotFoundError) Error() string {
+	return fmt.Sprintf("Account %x does not exist.", e.Address[:8])
+}
+
+//Returned by GetAccount if the account's state could not be computed, e.g. because the network did not respond.
+type AccountStateError struct {
+	Address [32]byte
+	Err     error
+}
+
+func (e *AccountStateError) Error() string {
+	return fmt.Sprintf("Could not calculate state of account %x: %v", e.Address[:8], e.Err)
+}
+
+func (e *AccountStateError) Unwrap() error {
+	return e.Err
+}
+
+func CheckAccount(ctx context.Context, address [32]byte) (*Account, []*TxHistoryEntry, error) {
+	LoadBlockHeaders()
+	return GetAccount(ctx, address)
+}
+
+//Returns the account's state and its complete transaction history, ordered from the youngest to the oldest entry. The
+//blocks are fetched until ctx is done.
+func GetAccount(ctx context.Context, address [32]byte) (*Account, []*TxHistoryEntry, error) {
 	//Initialize new account with empty address
-	account := Account{address, hex.EncodeToString(address[:]), 0, 0, false, false, false}
-
-	//Set default params
-	activeParameters = miner.NewDefaultParameters()
-
-	network.AccReq(false, protocol.SerializeHashContent(account.Address))
-	if accI, _ := network.Fetch(network.AccChan); accI != nil {
-		if acc := accI.(*protocol.Account); acc != nil {
-			account.IsCreated = true
-			account.IsStaking = acc.IsStaking
-
-			//If Acc is Root in the bazo network state, we do not check for accTx, else we check
-			network.AccReq(true, protocol.SerializeHashContent(account.Address))
-			if rootAccI, _ := network.Fetch(network.AccChan); rootAccI != nil {
-				if rootAcc := rootAccI.(*protocol.Account); rootAcc != nil {
-					account.IsRoot = true
-				}
-			}
-		}
+	account := Account{Address: address, AddressString: hex.EncodeToString(address[:])}
+
+	//The staking state is derived from the account's stake transactions. The miner's state is only used for comparison.
+	var minerIsStaking bool
+
+	acc, err := network.GetAccount(ctx, false, protocol.SerializeHashContent(account.Address))
+	if errors.Is(err, network.ErrNotFound) {
+		return nil, nil, &AccountNotFoundError{account.Address}
+	}
+
+	if err != nil {
+		return nil, nil, &AccountStateError{account.Address, err}
+	}
+
+	account.IsCreated = true
+	minerIsStaking = acc.IsStaking
+
+	//If Acc is Root in the bazo network state, we do not check for accTx, else we check
+	rootAcc, err := network.GetAccount(ctx, true, protocol.SerializeHashContent(account.Address))
+	if err != nil && !errors.Is(err, network.ErrNotFound) {
+		return nil, nil, &AccountStateError{account.Address, err}
+	}
+
+	account.IsRoot = rootAcc != nil
+
+	history, err := getState(ctx, &account)
+	if err != nil {
+		return nil, nil, &AccountStateError{account.Address, err}
 	}
 
-	if account.IsCreated == false {
-		return nil, nil, errors.New(fmt.Sprintf("Account %x does not exist.\n", account.Address[:8]))
+	if account.IsStaking != minerIsStaking {
+		logger.Printf("Staking state of account %x differs from the miner's state (chain: %v, miner: %v)\n", account.Address[:8], account.IsStaking, minerIsStaking)
 	}
 
-	//if account.IsStaking == true {
-	//	return nil, nil, errors.New(fmt.Sprintf("Account %x is a validator account. Validator's state cannot be calculated at the moment. We are sorry.\n", account.Address[:8]))
-	//}
+	return &account, history, nil
+}
+
+func CheckAccountHistory(ctx context.Context, address [32]byte, cursor string, limit int) ([]*TxHistoryEntry, string, error) {
+	LoadBlockHeaders()
+	return GetAccountHistory(ctx, address, cursor, limit)
+}
+
+//The history of the last listing of an account. The following pages reuse it while no header was added to the chain.
+type cachedHistory struct {
+	lastHeader [32]byte
+	history    []*TxHistoryEntry
+	usedAt     time.Time
+}
+
+var (
+	historyCache      = make(map[[32]byte]*cachedHistory)
+	historyCacheMutex = &sync.Mutex{}
+)
+
+//Returns one page of the account's transaction history, see PaginateHistory. The first page computes the account's
+//state, the following pages only do so if the chain changed in the meantime.
+func GetAccountHistory(ctx context.Context, address [32]byte, cursor string, limit int) (history []*TxHistoryEntry, nextCursor string, err error) {
+	var lastHeader [32]byte
+	if last := getLastBlockHeader(); last != nil {
+		lastHeader = last.Hash
+	}
+
+	historyCacheMutex.Lock()
+	cached := historyCache[address]
+	if cached != nil {
+		cached.usedAt = time.Now()
+	}
+	historyCacheMutex.Unlock()
+
+	if len(cursor) > 0 && cached != nil && cached.lastHeader == lastHeader {
+		return PaginateHistory(cached.history, cursor, limit)
+	}
 
-	var lastTenTx = make([]*FundsTxJson, 10)
-	err := getState(&account, lastTenTx)
+	_, history, err = GetAccount(ctx, address)
 	if err != nil {
-		return nil, nil, errors.New(fmt.Sprintf("Could not calculate state of account %x: %v\n", account.Address[:8], err))
+		return nil, "", err
+	}
+
+	cacheHistory(address, &cachedHistory{lastHeader, history, time.Now()})
+
+	return PaginateHistory(history, cursor, limit)
+}
+
+//Histories of an older chain are never reused, they are dropped. Of the others, at most util.HISTORY_CACHE_SIZE are
+//kept, the least recently used one is dropped first.
+func cacheHistory(address [32]byte, cached *cachedHistory) {
+	historyCacheMutex.Lock()
+	defer historyCacheMutex.Unlock()
+
+	for cachedAddress, other := range historyCache {
+		if other.lastHeader != cached.lastHeader {
+			delete(historyCache, cachedAddress)
+		}
 	}
 
-	//No accTx exists for this account since it is the initial root account
-	//Add the initial root's balance
-	//if account.IsCreated == false && account.IsRoot == true {
-	//	account.IsCreated = true
-	//}
+	delete(historyCache, address)
+	for len(historyCache) >= util.HISTORY_CACHE_SIZE {
+		var oldest [32]byte
+		var oldestUse time.Time
+		for cachedAddress, other := range historyCache {
+			if oldestUse.IsZero() || other.usedAt.Before(oldestUse) {
+				oldest, oldestUse = cachedAddress, other.usedAt
+			}
+		}
+
+		delete(historyCache, oldest)
+	}
 
-	return &account, lastTenTx, nil
+	historyCache[address] = cached
 }
 
 func (acc Account) String() string {
 	addressHash := protocol.SerializeHashContent(acc.Address)
-	return fmt.Sprintf("Hash: %x, Address: %x, TxCnt: %v, Balance: %v, isCreated: %v, isRoot: %v", addressHash[:8], acc.Address[:8], acc.TxCnt, acc.Balance, acc.IsCreated, acc.IsRoot)
+	return fmt.Sprintf("Hash: %x, Address: %x, TxCnt: %v, Balance: %v, isCreated: %v, isRoot: %v, isStaking: %v, createdAtHeight: %v, issuer: %v", addressHash[:8], acc.Address[:8], acc.TxCnt, acc.Balance, acc.IsCreated, acc.IsRoot, acc.IsStaking, acc.CreatedAtHeight, acc.Issuer)
 }
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"golang.org/x/crypto/ed25519"
+)
+
+//Checks of a tx before it is sent. A TxCheckError names the check which failed.
+const (
+	CHECK_SIGNATURE = "signature"
+	CHECK_FEE       = "fee"
+	CHECK_AMOUNT    = "amount"
+	CHECK_SENDER    = "sender"
+	CHECK_RECEIVER  = "receiver"
+	CHECK_ISSUER    = "issuer"
+)
+
+var (
+	ErrUnknownAccount = errors.New("account does not exist")
+	ErrAccountExists  = errors.New("account exists already")
+	ErrNotRoot        = errors.New("account is not a root account")
+	ErrNoRoot         = errors.New("the root's public key is required")
+)
+
+type TxCheckError struct {
+	TxHash [32]byte
+	Check  string
+	Err    error
+}
+
+func (e *TxCheckError) Error() string {
+	return fmt.Sprintf("Tx %x failed the %v check: %v", e.TxHash[:8], e.Check, e.Err)
+}
+
+func (e *TxCheckError) Unwrap() error {
+	return e.Err
+}
+
+//Check the signed tx before it is sent, so the miners are not bothered with txs they reject anyway. The signature is
+//verified against the signer's public key, unless the signer is empty. A ConfigTx names no issuer, so its signer must
+//be the root. The fee must reach the minimum fee of the synced chain and the accounts involved must exist. Returns a *TxCheckError if the tx is invalid, any other error means the
+//tx could not be checked.
+func CheckTx(ctx context.Context, tx protocol.Transaction, signer [32]byte) error {
+	txHash := tx.Hash()
+	newError := func(check string, err error) error {
+		return &TxCheckError{txHash, check, err}
+	}
+
+	if _, isConfigTx := tx.(*protocol.ConfigTx); isConfigTx && signer == [32]byte{} {
+		return newError(CHECK_SIGNATURE, ErrNoRoot)
+	}
+
+	if signer != [32]byte{} {
+		sig := TxSig(tx)
+		if !ed25519.Verify(signer[:], txHash[:], sig[:]) {
+			return newError(CHECK_SIGNATURE, errors.New(fmt.Sprintf("not signed by %x", signer)))
+		}
+	}
+
+	parameters, _, err := getNetworkParameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tx.TxFee() < parameters.Fee_minimum {
+		return newError(CHECK_FEE, errors.New(fmt.Sprintf("fee %v is below the minimum fee %v", tx.TxFee(), parameters.Fee_minimum)))
+	}
+
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		if tx.Amount == 0 {
+			return newError(CHECK_AMOUNT, errors.New("amount must be > 0"))
+		}
+
+		if signer != [32]byte{} && protocol.SerializeHashContent(signer) != tx.From {
+			return newError(CHECK_SIGNATURE, errors.New(fmt.Sprintf("%x is not the sender", signer)))
+		}
+
+		if exists, err := accountExists(ctx, false, tx.From); err != nil {
+			return err
+		} else if !exists {
+			return newError(CHECK_SENDER, ErrUnknownAccount)
+		}
+
+		if exists, err := accountExists(ctx, false, tx.To); err != nil {
+			return err
+		} else if !exists {
+			return newError(CHECK_RECEIVER, ErrUnknownAccount)
+		}
+	case *protocol.IotTx:
+		if exists, err := accountExists(ctx, false, tx.From); err != nil {
+			return err
+		} else if !exists {
+			return newError(CHECK_SENDER, ErrUnknownAccount)
+		}
+	case *protocol.AccTx:
+		if signer != [32]byte{} {
+			if isRoot, err := accountExists(ctx, true, protocol.SerializeHashContent(signer)); err != nil {
+				return err
+			} else if !isRoot {
+				return newError(CHECK_ISSUER, ErrNotRoot)
+			}
+		}
+
+		if exists, err := accountExists(ctx, false, protocol.SerializeHashContent(tx.PubKey)); err != nil {
+			return err
+		} else if exists {
+			return newError(CHECK_RECEIVER, ErrAccountExists)
+		}
+	case *protocol.ConfigTx:
+		if isRoot, err := accountExists(ctx, true, protocol.SerializeHashContent(signer)); err != nil {
+			return err
+		} else if !isRoot {
+			return newError(CHECK_ISSUER, ErrNotRoot)
+		}
+	}
+
+	return nil
+}
+
+//Returns false if the miner does not know the account or, if root is set, it is no root account. Returns an error if
+//the miner could not be asked.
+func accountExists(ctx context.Context, root bool, addressHash [32]byte) (bool, error) {
+	_, err := network.GetAccount(ctx, root, addressHash)
+	if errors.Is(err, network.ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+//Returns the signature of the tx, which is empty if the tx is not signed yet.
+func TxSig(tx protocol.Transaction) (sig [64]byte) {
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		return tx.Sig
+	case *protocol.AccTx:
+		return tx.Sig
+	case *protocol.ConfigTx:
+		return tx.Sig
+	case *protocol.StakeTx:
+		return tx.Sig
+	case *protocol.IotTx:
+		return tx.Sig
+	}
+
+	return sig
+}
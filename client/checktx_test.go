@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestCheckTxSignature(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherPubKey, otherPrivKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	var signer, otherSigner [32]byte
+	copy(signer[:], pubKey)
+	copy(otherSigner[:], otherPubKey)
+
+	sign := func(tx protocol.Transaction, key ed25519.PrivateKey) (sig [64]byte) {
+		txHash := tx.Hash()
+		copy(sig[:], ed25519.Sign(key, txHash[:]))
+		return sig
+	}
+
+	tests := []struct {
+		name   string
+		tx     func() protocol.Transaction
+		signer [32]byte
+	}{
+		{"FundsTx signed by another key", func() protocol.Transaction {
+			tx := &protocol.FundsTx{Amount: 1, Fee: 1, From: protocol.SerializeHashContent(signer)}
+			tx.Sig = sign(tx, otherPrivKey)
+			return tx
+		}, signer},
+		{"FundsTx without signature", func() protocol.Transaction {
+			return &protocol.FundsTx{Amount: 1, Fee: 1, From: protocol.SerializeHashContent(signer)}
+		}, signer},
+		{"FundsTx changed after signing", func() protocol.Transaction {
+			tx := &protocol.FundsTx{Amount: 1, Fee: 1, From: protocol.SerializeHashContent(signer)}
+			tx.Sig = sign(tx, privKey)
+			tx.Amount = 1000
+			return tx
+		}, signer},
+		{"AccTx signed for another root", func() protocol.Transaction {
+			tx := &protocol.AccTx{Fee: 1, PubKey: [32]byte{1}}
+			tx.Sig = sign(tx, privKey)
+			return tx
+		}, otherSigner},
+		{"ConfigTx with corrupted signature", func() protocol.Transaction {
+			tx := &protocol.ConfigTx{Id: 1, Payload: 10, Fee: 1}
+			tx.Sig = sign(tx, privKey)
+			tx.Sig[0] ^= 0xff
+			return tx
+		}, signer},
+		{"ConfigTx without root", func() protocol.Transaction {
+			tx := &protocol.ConfigTx{Id: 1, Payload: 10, Fee: 1}
+			tx.Sig = sign(tx, privKey)
+			return tx
+		}, [32]byte{}},
+		{"StakeTx signed by another key", func() protocol.Transaction {
+			tx := &protocol.StakeTx{Fee: 1, IsStaking: true, Account: protocol.SerializeHashContent(signer)}
+			tx.Sig = sign(tx, otherPrivKey)
+			return tx
+		}, signer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckTx(context.Background(), test.tx(), test.signer)
+
+			var checkErr *TxCheckError
+			if !errors.As(err, &checkErr) {
+				t.Fatalf("got %v, want a *TxCheckError", err)
+			}
+
+			if checkErr.Check != CHECK_SIGNATURE {
+				t.Errorf("check: got %v, want %v", checkErr.Check, CHECK_SIGNATURE)
+			}
+		})
+	}
+}
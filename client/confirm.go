@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sort"
+	"sync"
+)
+
+const (
+	TX_STATUS_PENDING   = "pending"
+	TX_STATUS_INCLUDED  = "included"
+	TX_STATUS_CONFIRMED = "confirmed"
+)
+
+//A tx is confirmed once the block including it is followed by enough blocks. The including block counts as the first
+//confirmation.
+type TxStatus struct {
+	TxHash        string `json:"txHash"`
+	Status        string `json:"status"`
+	BlockHash     string `json:"blockHash,omitempty"`
+	Height        uint32 `json:"height,omitempty"`
+	Confirmations uint32 `json:"confirmations"`
+}
+
+//The blocks up to checkedHeight do not include the tx. Only blocks whose bloom filter matches addressHash are fetched,
+//the zero hash matches every block. trackedHeight is the height of the last header when the tracking started.
+type trackedTx struct {
+	tx            protocol.Transaction
+	addressHash   [32]byte
+	trackedHeight uint32
+	checkedHeight uint32
+	included      bool
+	blockHash     [32]byte
+	height        uint32
+}
+
+var (
+	trackedTxs      = make(map[[32]byte]*trackedTx)
+	trackedTxsMutex = &sync.Mutex{}
+	trackerOnce     sync.Once
+
+	//Wakes the tracker up after headers were appended to the chain.
+	trackerUpdate = make(chan bool, 1)
+
+	//Closed and replaced after every update of the tracker, wakes up WaitForTx.
+	trackerChanged = make(chan struct{})
+)
+
+//Track the tx until it is included in a block. The blocks after the current last header are searched. Tracked txs are
+//kept in memory only, they are not tracked anymore after a restart. See pruneTrackedTxs for how long a tx is tracked.
+func TrackTx(tx protocol.Transaction) {
+	var addressHash [32]byte
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		addressHash = tx.From
+	case *protocol.AccTx:
+		addressHash = protocol.SerializeHashContent(tx.PubKey)
+	case *protocol.StakeTx:
+		addressHash = tx.Account
+	}
+
+	tracked := &trackedTx{tx: tx, addressHash: addressHash}
+	if last := getLastBlockHeader(); last != nil {
+		tracked.trackedHeight = last.Height
+		tracked.checkedHeight = last.Height
+	}
+
+	trackedTxsMutex.Lock()
+	if _, exists := trackedTxs[tx.Hash()]; !exists {
+		trackedTxs[tx.Hash()] = tracked
+	}
+	trackedTxsMutex.Unlock()
+
+	trackerOnce.Do(func() {
+		go trackTxs()
+	})
+
+	notifyTxTracker()
+}
+
+//Returns false if the tx is not tracked. The status is confirmed once the tx has at least the given confirmations.
+func GetTxStatus(txHash [32]byte, confirmations uint32) (*TxStatus, bool) {
+	trackedTxsMutex.Lock()
+	defer trackedTxsMutex.Unlock()
+
+	tracked := trackedTxs[txHash]
+	if tracked == nil {
+		return nil, false
+	}
+
+	status := &TxStatus{TxHash: hex.EncodeToString(txHash[:]), Status: TX_STATUS_PENDING}
+	if tracked.included {
+		status.Status = TX_STATUS_INCLUDED
+		status.BlockHash = hex.EncodeToString(tracked.blockHash[:])
+		status.Height = tracked.height
+
+		if last := getLastBlockHeader(); last != nil {
+			status.Confirmations = last.Height - tracked.height + 1
+		}
+
+		if status.Confirmations >= confirmations {
+			status.Status = TX_STATUS_CONFIRMED
+		}
+	}
+
+	return status, true
+}
+
+//Block until the tracked tx has the given number of confirmations or ctx is done. Returns the last known status.
+func WaitForTx(ctx context.Context, txHash [32]byte, confirmations uint32) (*TxStatus, error) {
+	for {
+		trackedTxsMutex.Lock()
+		changed := trackerChanged
+		trackedTxsMutex.Unlock()
+
+		status, _ := GetTxStatus(txHash, confirmations)
+		if status != nil && status.Status == TX_STATUS_CONFIRMED {
+			return status, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return status, ctx.Err()
+		}
+	}
+}
+
+func notifyTxTracker() {
+	select {
+	case trackerUpdate <- true:
+	default:
+	}
+}
+
+func trackTxs() {
+	reorgs := SubscribeReorgs()
+
+	for {
+		select {
+		case <-trackerUpdate:
+		case event := <-reorgs:
+			untrackOrphaned(event)
+		}
+
+		updateTrackedTxs()
+
+		trackedTxsMutex.Lock()
+		if last := getLastBlockHeader(); last != nil {
+			pruneTrackedTxs(last.Height)
+		}
+		close(trackerChanged)
+		trackerChanged = make(chan struct{})
+		trackedTxsMutex.Unlock()
+	}
+}
+
+//Txs of orphaned blocks are pending again. The new branch is searched from the common ancestor on.
+func untrackOrphaned(event *ReorgEvent) {
+	trackedTxsMutex.Lock()
+	defer trackedTxsMutex.Unlock()
+
+	orphaned := make(map[[32]byte]bool)
+	for _, hash := range event.Orphaned {
+		orphaned[hash] = true
+	}
+
+	for _, tracked := range trackedTxs {
+		if tracked.included && orphaned[tracked.blockHash] {
+			tracked.included = false
+			tracked.blockHash = [32]byte{}
+			tracked.height = 0
+		}
+
+		if !tracked.included && tracked.checkedHeight > event.AncestorHeight {
+			tracked.checkedHeight = event.AncestorHeight
+		}
+	}
+}
+
+//A tx is not tracked anymore once its block is followed by util.TX_TRACKING_BLOCKS blocks, a reorg does not orphan it
+//then. A tx which is not included within util.TX_TRACKING_BLOCKS blocks is dropped, e.g. because the miners rejected
+//it. Called with trackedTxsMutex held.
+func pruneTrackedTxs(height uint32) {
+	for txHash, tracked := range trackedTxs {
+		if (tracked.included && height >= tracked.height+util.TX_TRACKING_BLOCKS) ||
+			(!tracked.included && height >= tracked.trackedHeight+util.TX_TRACKING_BLOCKS) {
+			delete(trackedTxs, txHash)
+		}
+	}
+}
+
+//Search the blocks after the checked height of every pending tx. Each block is fetched at most once per update.
+func updateTrackedTxs() {
+	headers := getBlockHeaders()
+	blocks := make(map[[32]byte]*protocol.Block)
+
+	trackedTxsMutex.Lock()
+	pending := make(map[[32]byte]trackedTx)
+	for txHash, tracked := range trackedTxs {
+		if !tracked.included {
+			pending[txHash] = *tracked
+		}
+	}
+	trackedTxsMutex.Unlock()
+
+	for txHash, tracked := range pending {
+		start := sort.Search(len(headers), func(i int) bool {
+			return headers[i].Height > tracked.checkedHeight
+		})
+
+		for _, header := range headers[start:] {
+			if tracked.addressHash != [32]byte{} && !isRelevantBlockHeader(header, tracked.addressHash) {
+				tracked.checkedHeight = header.Height
+				continue
+			}
+
+			block := blocks[header.Hash]
+			if block == nil {
+				var err error
+				if block, err = network.GetBlock(context.Background(), header.Hash); err != nil {
+					logger.Printf("Searching tx %x in block %x failed: %v\n", txHash[:8], header.Hash[:8], err)
+					break
+				}
+
+				blocks[header.Hash] = block
+			}
+
+			//The inclusion is only accepted with a valid merkle proof.
+			if blockIncludesTx(block, txHash) {
+				if err := validateTx(context.Background(), block, tracked.tx, txHash); err != nil {
+					logger.Printf("Searching tx %x in block %x failed: %v\n", txHash[:8], header.Hash[:8], err)
+					break
+				}
+
+				tracked.included = true
+				tracked.blockHash = block.Hash
+				tracked.height = block.Height
+				break
+			}
+
+			tracked.checkedHeight = header.Height
+		}
+
+		//Reorgs are handled by the same goroutine, so the tracked tx did not change in the meantime.
+		trackedTxsMutex.Lock()
+		*trackedTxs[txHash] = tracked
+		trackedTxsMutex.Unlock()
+	}
+}
+
+func blockIncludesTx(block *protocol.Block, txHash [32]byte) bool {
+	for _, hashes := range [][][32]byte{block.FundsTxData, block.AccTxData, block.ConfigTxData, block.StakeTxData, block.IotTxData} {
+		for _, hash := range hashes {
+			if hash == txHash {
+				return true
+			}
+		}
+	}
+
+	return false
+}
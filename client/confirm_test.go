@@ -0,0 +1,39 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-client/util"
+)
+
+func TestPruneTrackedTxs(t *testing.T) {
+	defer func() { trackedTxs = make(map[[32]byte]*trackedTx) }()
+
+	const height = 5000
+
+	tests := []struct {
+		name    string
+		tracked *trackedTx
+		kept    bool
+	}{
+		{"pending", &trackedTx{trackedHeight: height - 10, checkedHeight: height}, true},
+		{"pending for too long", &trackedTx{trackedHeight: height - util.TX_TRACKING_BLOCKS, checkedHeight: height}, false},
+		{"pending ConfigTx for too long", &trackedTx{trackedHeight: height - util.TX_TRACKING_BLOCKS - 1}, false},
+		{"included", &trackedTx{trackedHeight: 10, included: true, height: height - 10}, true},
+		{"confirmed", &trackedTx{trackedHeight: 10, included: true, height: height - util.TX_TRACKING_BLOCKS + 1}, true},
+		{"deeply buried", &trackedTx{trackedHeight: 10, included: true, height: height - util.TX_TRACKING_BLOCKS}, false},
+	}
+
+	trackedTxs = make(map[[32]byte]*trackedTx)
+	for i, test := range tests {
+		trackedTxs[[32]byte{byte(i)}] = test.tracked
+	}
+
+	pruneTrackedTxs(height)
+
+	for i, test := range tests {
+		if _, kept := trackedTxs[[32]byte{byte(i)}]; kept != test.kept {
+			t.Errorf("%v: got kept %v, want %v", test.name, kept, test.kept)
+		}
+	}
+}
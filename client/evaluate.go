@@ -1,40 +1,71 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 )
 
-func getRelevantBlocks(relevantBlockHeaders []*protocol.Block) (relevantBlocks []*protocol.Block, err error) {
+//Fetch the blocks of the given headers. A block which does not match its header is dropped like a block the miner does
+//not know, so the returned slice holds nil for both.
+func getRelevantBlocks(ctx context.Context, relevantBlockHeaders []*protocol.Block) (relevantBlocks []*protocol.Block, err error) {
 	for _, blockHeader := range relevantBlockHeaders {
-		err := network.BlockReq(blockHeader.Hash[:])
-		if err != nil {
+		block, err := network.GetBlock(ctx, blockHeader.Hash)
+		if err != nil && !errors.Is(err, network.ErrNotFound) {
 			return nil, err
 		}
 
-		blockI, err := network.Fetch(network.BlockChan)
-		if err != nil {
-			return nil, err
+		if block != nil {
+			if err := checkBlock(block, blockHeader); err != nil {
+				logger.Printf("Block %x rejected: %v\n", blockHeader.Hash[:8], err)
+				block = nil
+			}
 		}
 
-		var block *protocol.Block
-		block = blockI.(*protocol.Block)
 		relevantBlocks = append(relevantBlocks, block)
 	}
 
 	return relevantBlocks, nil
 }
 
-func getRelevantBlockHeaders(pubKeyHash [32]byte) (relevantHeadersBeneficiary []*protocol.Block, relevantHeadersConfigBF []*protocol.Block) {
-	for _, blockHeader := range blockHeaders {
+//The block must be the one of the verified header. The header does not carry the merkle root, but the block's hash
+//covers it, so validateTx checks every tx against the root of a block which passed this check.
+func checkBlock(block *protocol.Block, blockHeader *protocol.Block) error {
+	if block.Hash != blockHeader.Hash || block.HashBlock() != blockHeader.Hash {
+		return errors.New(fmt.Sprintf("hash %x does not match header %x", block.Hash[:8], blockHeader.Hash[:8]))
+	}
+
+	if block.PrevHash != blockHeader.PrevHash || block.Height != blockHeader.Height {
+		return errors.New("previous hash or height does not match header")
+	}
+
+	if block.Beneficiary != blockHeader.Beneficiary {
+		return errors.New(fmt.Sprintf("beneficiary %x does not match header", block.Beneficiary[:8]))
+	}
+
+	return nil
+}
+
+func getRelevantBlockHeaders(headers []*protocol.Block, pubKeyHash [32]byte) (relevantHeadersBeneficiary []*protocol.Block, relevantHeadersConfigBF []*protocol.Block) {
+	for _, blockHeader := range headers {
 		if blockHeader.Beneficiary == pubKeyHash {
 			relevantHeadersBeneficiary = append(relevantHeadersBeneficiary, blockHeader)
 		}
 
-		if blockHeader.NrConfigTx > 0 || (blockHeader.NrElementsBF > 0 && blockHeader.BloomFilter.Test(pubKeyHash[:])) {
+		if isRelevantBlockHeader(blockHeader, pubKeyHash) {
 			relevantHeadersConfigBF = append(relevantHeadersConfigBF, blockHeader)
 		}
 	}
 
 	return relevantHeadersBeneficiary, relevantHeadersConfigBF
 }
+
+func isRelevantBlockHeader(blockHeader *protocol.Block, pubKeyHash [32]byte) bool {
+	return blockHeader.NrConfigTx > 0 || bloomFilterContains(blockHeader, pubKeyHash)
+}
+
+func bloomFilterContains(blockHeader *protocol.Block, pubKeyHash [32]byte) bool {
+	return blockHeader.NrElementsBF > 0 && blockHeader.BloomFilter != nil && blockHeader.BloomFilter.Test(pubKeyHash[:])
+}
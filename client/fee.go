@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sort"
+	"sync"
+)
+
+type FeeEstimate struct {
+	Minimum     uint64 `json:"minimum"`
+	Median      uint64 `json:"median"`
+	Recommended uint64 `json:"recommended"`
+	Blocks      int    `json:"blocks"`
+	Txs         int    `json:"txs"`
+}
+
+var (
+	//Fees of the FundsTxs of recent blocks, so repeated estimates only fetch new blocks.
+	blockFees      = make(map[[32]byte][]uint64)
+	blockFeesMutex = &sync.Mutex{}
+)
+
+//Estimates the fee of a tx from the FundsTxs of the last util.FEE_ESTIMATE_BLOCKS blocks. The recommended fee is the
+//median of their fees, but not less than the network's minimum fee. Without recent txs, the minimum fee is recommended.
+func EstimateFee(ctx context.Context) (*FeeEstimate, error) {
+	parameters, _, err := getNetworkParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := getBlockHeaders()
+	if len(headers) > util.FEE_ESTIMATE_BLOCKS {
+		headers = headers[len(headers)-util.FEE_ESTIMATE_BLOCKS:]
+	}
+
+	blockFeesMutex.Lock()
+	defer blockFeesMutex.Unlock()
+
+	recentFees := make(map[[32]byte][]uint64)
+	var fees []uint64
+	for i := len(headers) - 1; i >= 0 && len(fees) < util.FEE_ESTIMATE_TXS; i-- {
+		//Headers do not carry the number of FundsTxs, so every block is fetched once.
+		blockHeader := headers[i]
+		feesOfBlock, cached := blockFees[blockHeader.Hash]
+		if !cached {
+			if feesOfBlock, err = getBlockFees(ctx, blockHeader.Hash); err != nil {
+				return nil, err
+			}
+		}
+
+		recentFees[blockHeader.Hash] = feesOfBlock
+		fees = append(fees, feesOfBlock...)
+	}
+
+	//Blocks which are not recent anymore are dropped from the cache.
+	blockFees = recentFees
+
+	return newFeeEstimate(fees, parameters.Fee_minimum, len(headers)), nil
+}
+
+//The median is the upper median if the number of fees is even.
+func newFeeEstimate(fees []uint64, minimum uint64, blocks int) *FeeEstimate {
+	estimate := &FeeEstimate{Minimum: minimum, Blocks: blocks, Txs: len(fees)}
+	if len(fees) > 0 {
+		sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+		estimate.Median = fees[len(fees)/2]
+	}
+
+	estimate.Recommended = estimate.Median
+	if estimate.Recommended < estimate.Minimum {
+		estimate.Recommended = estimate.Minimum
+	}
+
+	//A fee of 0 is never accepted.
+	if estimate.Recommended == 0 {
+		estimate.Recommended = 1
+	}
+
+	return estimate
+}
+
+func getBlockFees(ctx context.Context, blockHash [32]byte) (fees []uint64, err error) {
+	block, err := network.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, txHash := range block.FundsTxData {
+		tx, err := network.GetTx(ctx, p2p.FUNDSTX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if fundsTx, ok := tx.(*protocol.FundsTx); ok {
+			fees = append(fees, fundsTx.Fee)
+		}
+	}
+
+	return fees, nil
+}
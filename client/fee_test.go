@@ -0,0 +1,40 @@
+package client
+
+import "testing"
+
+func TestNewFeeEstimate(t *testing.T) {
+	tests := []struct {
+		name        string
+		fees        []uint64
+		minimum     uint64
+		median      uint64
+		recommended uint64
+	}{
+		{"no txs", nil, 5, 0, 5},
+		{"no txs and no minimum", nil, 0, 0, 1},
+		{"single tx", []uint64{7}, 1, 7, 7},
+		{"odd number of txs", []uint64{9, 1, 5}, 1, 5, 5},
+		{"even number of txs", []uint64{4, 1, 3, 2}, 1, 3, 3},
+		{"median below minimum", []uint64{1, 2, 3}, 10, 2, 10},
+		{"outliers", []uint64{1, 1, 1, 1000000}, 1, 1, 1},
+		{"zero fees", []uint64{0, 0, 0}, 0, 0, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			estimate := newFeeEstimate(test.fees, test.minimum, 20)
+
+			if estimate.Median != test.median {
+				t.Errorf("median: got %v, want %v", estimate.Median, test.median)
+			}
+
+			if estimate.Recommended != test.recommended {
+				t.Errorf("recommended: got %v, want %v", estimate.Recommended, test.recommended)
+			}
+
+			if estimate.Txs != len(test.fees) || estimate.Blocks != 20 || estimate.Minimum != test.minimum {
+				t.Errorf("got %+v", estimate)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/ed25519"
+)
+
+//Returns a new BIP-39 mnemonic of 24 words.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+func HasHDWallet() bool {
+	return cstorage.ReadHDSeed() != nil
+}
+
+//Store the seed of the mnemonic, encrypted with the passphrase. Every key derived afterwards is recovered by
+//initializing the HD wallet with the same mnemonic again.
+func InitHDWallet(mnemonic string, passphrase []byte) error {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return err
+	}
+
+	encryptedSeed, err := util.EncryptSecret(seed, passphrase, nil)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(encryptedSeed)
+	if err != nil {
+		return err
+	}
+
+	return cstorage.WriteHDSeed(encoded)
+}
+
+//Derive the key with the given index from the seed of the HD wallet, see util.DeriveEDKey.
+func DeriveKey(index uint32, passphrase []byte) (ed25519.PrivateKey, error) {
+	encoded := cstorage.ReadHDSeed()
+	if encoded == nil {
+		return nil, errors.New("HD wallet not initialized, run wallet init first.")
+	}
+
+	encryptedSeed := new(util.EncryptedSecret)
+	if err := json.Unmarshal(encoded, encryptedSeed); err != nil {
+		return nil, err
+	}
+
+	seed, err := encryptedSeed.Decrypt(passphrase, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.DeriveEDKey(seed, index)
+}
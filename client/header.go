@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+//Checks of the header validation. A HeaderValidationError names the check which failed.
+const (
+	CHECK_LINKAGE    = "linkage"
+	CHECK_HEIGHT     = "height"
+	CHECK_VALIDATOR  = "validator"
+	CHECK_STAKE      = "stake"
+	CHECK_COMMITMENT = "commitment proof"
+)
+
+type HeaderValidationError struct {
+	Hash   [32]byte
+	Height uint32
+	Check  string
+	Err    error
+}
+
+func (e *HeaderValidationError) Error() string {
+	return fmt.Sprintf("Header %x with height %v failed the %v check: %v", e.Hash[:8], e.Height, e.Check, e.Err)
+}
+
+func (e *HeaderValidationError) Unwrap() error {
+	return e.Err
+}
+
+//Validate the header against the chain preceding it, from the oldest to the youngest header. The chain is empty only
+//for the genesis header. A header does not carry the timestamp and the merkle root its hash is computed from, so only
+//its linkage, its height and the beneficiary's commitment proof are checked. The beneficiary is only verified if
+//validators is not nil. Errors which are no HeaderValidationError leave the header's validity open, e.g. if a block
+//could not be fetched.
+func validateBlockHeader(ctx context.Context, header *protocol.Block, chain []*protocol.Block, validators *validatorSet, parameters miner.Parameters) error {
+	newError := func(check string, err error) error {
+		return &HeaderValidationError{header.Hash, header.Height, check, err}
+	}
+
+	if len(chain) == 0 {
+		if header.Height != 0 || header.PrevHash != [32]byte{} {
+			return newError(CHECK_LINKAGE, errors.New(fmt.Sprintf("predecessor %x not found", header.PrevHash[:8])))
+		}
+
+		return nil
+	}
+
+	prev := chain[len(chain)-1]
+	if header.PrevHash != prev.Hash {
+		return newError(CHECK_LINKAGE, errors.New(fmt.Sprintf("previous hash %x does not match predecessor %x", header.PrevHash[:8], prev.Hash[:8])))
+	}
+
+	if header.Height != prev.Height+1 {
+		return newError(CHECK_HEIGHT, errors.New(fmt.Sprintf("predecessor has height %v", prev.Height)))
+	}
+
+	if validators == nil {
+		return nil
+	}
+
+	v, err := validators.stakeOf(ctx, chain, header.Beneficiary)
+	if err != nil {
+		return err
+	}
+
+	if check, err := verifyBeneficiary(header, v, parameters); err != nil {
+		return newError(check, err)
+	}
+
+	return nil
+}
+
+//Validate all headers of the chain stored in the database. Returns the number of valid headers preceding the first
+//invalid one.
+func VerifyBlockHeaders(ctx context.Context) (verified int, err error) {
+	last := cstorage.ReadLastBlockHeader()
+	if last == nil {
+		return 0, nil
+	}
+
+	loaded, err := loadDB(last, [32]byte{})
+	if err != nil {
+		return 0, err
+	}
+
+	parameters, _, err := getNetworkParameters(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	//The stake of the beneficiaries is derived again, independent of the synced chain.
+	var validators *validatorSet
+	if chainValidators != nil {
+		if validators, err = newValidatorSet(util.Config.GenesisValidators); err != nil {
+			return 0, err
+		}
+	}
+
+	for i, header := range loaded {
+		if err := validateBlockHeader(ctx, header, loaded[:i], validators, parameters); err != nil {
+			return verified, err
+		}
+
+		verified++
+	}
+
+	return verified, nil
+}
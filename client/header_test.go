@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestValidateBlockHeader(t *testing.T) {
+	staking, other := [32]byte{1}, [32]byte{2}
+	validators := &validatorSet{
+		genesis: map[[32]byte]*validator{staking: {isStaking: true}},
+		tracked: make(map[[32]byte]*trackedValidator),
+	}
+
+	//The headers are validated as received from the network, without timestamp and merkle root.
+	header := func(height uint32, prevHash [32]byte, beneficiary [32]byte) *protocol.Block {
+		block := &protocol.Block{Height: height, PrevHash: prevHash, Beneficiary: beneficiary, Timestamp: 1554710000, MerkleRoot: [32]byte{0xaa}}
+		block.Hash = block.HashBlock()
+
+		return block.Decode(block.EncodeHeader())
+	}
+
+	genesis := header(0, [32]byte{}, staking)
+	second := header(1, genesis.Hash, staking)
+	chain := []*protocol.Block{genesis, second}
+
+	tests := []struct {
+		name       string
+		header     *protocol.Block
+		chain      []*protocol.Block
+		validators *validatorSet
+		check      string
+	}{
+		{"genesis header", genesis, nil, validators, ""},
+		{"genesis header with predecessor", header(0, [32]byte{7}, staking), nil, validators, CHECK_LINKAGE},
+		{"valid header", header(2, second.Hash, staking), chain, validators, ""},
+		{"unknown predecessor", header(2, [32]byte{7}, staking), chain, validators, CHECK_LINKAGE},
+		{"wrong height", header(3, second.Hash, staking), chain, validators, CHECK_HEIGHT},
+		{"beneficiary not staking", header(2, second.Hash, other), chain, validators, CHECK_VALIDATOR},
+		{"beneficiaries not verified", header(2, second.Hash, other), chain, nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateBlockHeader(context.Background(), test.header, test.chain, test.validators, miner.NewDefaultParameters())
+
+			var validationErr *HeaderValidationError
+			if test.check == "" && err != nil {
+				t.Errorf("got error %v", err)
+			} else if test.check != "" && (!errors.As(err, &validationErr) || validationErr.Check != test.check) {
+				t.Errorf("got error %v, want check %v", err, test.check)
+			}
+		})
+	}
+}
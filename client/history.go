@@ -0,0 +1,203 @@
+package client
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sort"
+)
+
+const (
+	HISTORY_DEFAULT_LIMIT = 10
+	HISTORY_MAX_LIMIT     = 100
+
+	TX_TYPE_FUNDS  = "funds"
+	TX_TYPE_ACC    = "acc"
+	TX_TYPE_CONFIG = "config"
+	TX_TYPE_STAKE  = "stake"
+	TX_TYPE_REWARD = "reward"
+
+	DIRECTION_INBOUND  = "inbound"
+	DIRECTION_OUTBOUND = "outbound"
+	DIRECTION_FEE      = "fee"
+)
+
+//A single balance change of an account. Transactions the account is involved in multiple times (e.g. sending funds in
+//a block it mined) appear once per direction.
+type TxHistoryEntry struct {
+	Id        string       `json:"id"`
+	Type      string       `json:"type"`
+	Direction string       `json:"direction"`
+	Height    uint32       `json:"height"`
+	BlockHash string       `json:"blockHash,omitempty"`
+	TxHash    string       `json:"txHash,omitempty"`
+	Amount    uint64       `json:"amount"`
+	Fee       uint64       `json:"fee"`
+	Status    string       `json:"status"`
+	FundsTx   *FundsTxJson `json:"fundsTx,omitempty"`
+}
+
+func newTxHistoryEntry(txType string, direction string, height uint32, blockHash [32]byte, txHash [32]byte, amount uint64, fee uint64, status string) *TxHistoryEntry {
+	//The id is unique per entry. It is built from the tx hash and the direction, so it does not change once a pending tx
+	//is included in a block or its block is replaced by a reorg. Rewards are identified by their block.
+	anchor := txHash
+	if anchor == [32]byte{} {
+		anchor = blockHash
+	}
+
+	var id [32 + 1]byte
+	copy(id[:32], anchor[:])
+	id[32] = directionId(direction)
+
+	entry := &TxHistoryEntry{
+		Id:        hex.EncodeToString(id[:]),
+		Type:      txType,
+		Direction: direction,
+		Height:    height,
+		Amount:    amount,
+		Fee:       fee,
+		Status:    status,
+	}
+
+	if blockHash != [32]byte{} {
+		entry.BlockHash = hex.EncodeToString(blockHash[:])
+	}
+
+	if txHash != [32]byte{} {
+		entry.TxHash = hex.EncodeToString(txHash[:])
+	}
+
+	return entry
+}
+
+//The block is nil for transactions which are not verified yet.
+func newFundsTxHistoryEntry(tx *protocol.FundsTx, direction string, block *protocol.Block, status string) *TxHistoryEntry {
+	var height uint32
+	var blockHash [32]byte
+	if block != nil {
+		height = block.Height
+		blockHash = block.Hash
+	}
+
+	var amount uint64
+	switch direction {
+	case DIRECTION_INBOUND, DIRECTION_OUTBOUND:
+		amount = tx.Amount
+	}
+
+	entry := newTxHistoryEntry(TX_TYPE_FUNDS, direction, height, blockHash, tx.Hash(), amount, tx.Fee, status)
+	entry.FundsTx = ConvertFundsTx(tx, status)
+
+	return entry
+}
+
+func (entry TxHistoryEntry) String() string {
+	return fmt.Sprintf("Id: %v, Type: %v, Direction: %v, Height: %v, TxHash: %v, Amount: %v, Fee: %v, Status: %v",
+		entry.Id, entry.Type, entry.Direction, entry.Height, entry.TxHash, entry.Amount, entry.Fee, entry.Status)
+}
+
+func directionId(direction string) byte {
+	switch direction {
+	case DIRECTION_INBOUND:
+		return 1
+	case DIRECTION_OUTBOUND:
+		return 2
+	case DIRECTION_FEE:
+		return 3
+	}
+
+	return 0
+}
+
+//Order the history from the youngest to the oldest entry. Not verified entries come first.
+func sortHistory(history []*TxHistoryEntry) {
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].Status != history[j].Status {
+			return history[i].Status == "not verified"
+		}
+
+		return history[i].Height > history[j].Height
+	})
+}
+
+//Return at most limit entries following the entry of the cursor, see historyCursor. An empty cursor starts at the
+//youngest entry. The returned next cursor is empty if there are no further entries.
+func PaginateHistory(history []*TxHistoryEntry, cursor string, limit int) (page []*TxHistoryEntry, nextCursor string, err error) {
+	if limit <= 0 {
+		limit = HISTORY_DEFAULT_LIMIT
+	}
+
+	if limit > HISTORY_MAX_LIMIT {
+		limit = HISTORY_MAX_LIMIT
+	}
+
+	start := 0
+	if len(cursor) > 0 {
+		id, height, err := parseHistoryCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		start = -1
+		for i, entry := range history {
+			if entry.Id == id {
+				start = i + 1
+				break
+			}
+		}
+
+		//The entry is not part of the history anymore, e.g. its block was orphaned or the pending tx was dropped. The
+		//listing continues with the verified entries below its height.
+		if start == -1 {
+			start = len(history)
+			for i, entry := range history {
+				if entry.Status != "not verified" && (height == 0 || entry.Height < height) {
+					start = i
+					break
+				}
+			}
+		}
+	}
+
+	end := start + limit
+	if end > len(history) {
+		end = len(history)
+	}
+
+	page = history[start:end]
+	if end < len(history) {
+		nextCursor = historyCursor(page[len(page)-1])
+	}
+
+	return page, nextCursor, nil
+}
+
+//The cursor is the entry's id followed by its height, which is 0 for pending entries. The height is the fallback
+//position if the entry is not part of the history anymore.
+func historyCursor(entry *TxHistoryEntry) string {
+	var height [4]byte
+	binary.BigEndian.PutUint32(height[:], entry.Height)
+
+	return entry.Id + hex.EncodeToString(height[:])
+}
+
+//Returns an error if the cursor is neither empty nor a cursor returned by PaginateHistory.
+func ValidateHistoryCursor(cursor string) error {
+	if len(cursor) == 0 {
+		return nil
+	}
+
+	_, _, err := parseHistoryCursor(cursor)
+	return err
+}
+
+func parseHistoryCursor(cursor string) (id string, height uint32, err error) {
+	decoded, err := hex.DecodeString(cursor)
+	if err != nil || len(decoded) != 32+1+4 {
+		return "", 0, errors.New(fmt.Sprintf("Invalid cursor %v", cursor))
+	}
+
+	return hex.EncodeToString(decoded[:33]), binary.BigEndian.Uint32(decoded[33:]), nil
+}
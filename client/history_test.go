@@ -0,0 +1,135 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bazo-blockchain/bazo-client/util"
+)
+
+func testHistoryEntry(tx byte, height uint32) *TxHistoryEntry {
+	if height == 0 {
+		return newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_INBOUND, 0, [32]byte{}, [32]byte{tx}, 1, 1, "not verified")
+	}
+
+	return newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_INBOUND, height, [32]byte{byte(height)}, [32]byte{tx}, 1, 1, "verified")
+}
+
+func TestPaginateHistory(t *testing.T) {
+	pending := testHistoryEntry(1, 0)
+	a := testHistoryEntry(2, 30)
+	b := testHistoryEntry(3, 20)
+	c := testHistoryEntry(4, 20)
+	d := testHistoryEntry(5, 10)
+	mined := testHistoryEntry(1, 40)
+	reward := newTxHistoryEntry(TX_TYPE_REWARD, DIRECTION_INBOUND, 30, [32]byte{30}, [32]byte{}, 10, 0, "verified")
+	fee := newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_FEE, 30, [32]byte{30}, [32]byte{2}, 0, 1, "verified")
+
+	history := []*TxHistoryEntry{pending, a, b, c, d}
+
+	tests := []struct {
+		name    string
+		history []*TxHistoryEntry
+		cursor  string
+		limit   int
+		page    []*TxHistoryEntry
+		next    string
+		wantErr bool
+	}{
+		{"empty history", nil, "", 10, nil, "", false},
+		{"first page", history, "", 2, []*TxHistoryEntry{pending, a}, historyCursor(a), false},
+		{"next page", history, historyCursor(a), 2, []*TxHistoryEntry{b, c}, historyCursor(c), false},
+		{"last page", history, historyCursor(c), 2, []*TxHistoryEntry{d}, "", false},
+		{"exact last page", history, historyCursor(b), 2, []*TxHistoryEntry{c, d}, "", false},
+		{"default limit", history, "", 0, history, "", false},
+		{"after last entry", history, historyCursor(d), 2, []*TxHistoryEntry{}, "", false},
+		{"pending tx got mined", []*TxHistoryEntry{mined, a, b, c, d}, historyCursor(pending), 2, []*TxHistoryEntry{a, b}, historyCursor(b), false},
+		{"orphaned block", []*TxHistoryEntry{pending, a, d}, historyCursor(b), 2, []*TxHistoryEntry{d}, "", false},
+		{"dropped pending tx", []*TxHistoryEntry{a, b, c, d}, historyCursor(pending), 1, []*TxHistoryEntry{a}, historyCursor(a), false},
+		{"directions of one tx", []*TxHistoryEntry{a, fee, reward, b}, historyCursor(a), 1, []*TxHistoryEntry{fee}, historyCursor(fee), false},
+		{"reward", []*TxHistoryEntry{a, fee, reward, b}, historyCursor(fee), 1, []*TxHistoryEntry{reward}, historyCursor(reward), false},
+		{"invalid hex", history, "xyz", 2, nil, "", true},
+		{"invalid length", history, a.Id, 2, nil, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, next, err := PaginateHistory(test.history, test.cursor, test.limit)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+
+			if len(page) != len(test.page) {
+				t.Fatalf("got %v entries, want %v", len(page), len(test.page))
+			}
+
+			for i := range page {
+				if page[i] != test.page[i] {
+					t.Errorf("entry %v: got %v, want %v", i, page[i], test.page[i])
+				}
+			}
+
+			if next != test.next {
+				t.Errorf("next cursor: got %v, want %v", next, test.next)
+			}
+
+			if err := ValidateHistoryCursor(test.cursor); (err != nil) != test.wantErr {
+				t.Errorf("validate: got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestHistoryEntryIdsAreUnique(t *testing.T) {
+	entries := []*TxHistoryEntry{
+		newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_INBOUND, 1, [32]byte{1}, [32]byte{2}, 1, 1, "verified"),
+		newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_OUTBOUND, 1, [32]byte{1}, [32]byte{2}, 1, 1, "verified"),
+		newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_FEE, 1, [32]byte{1}, [32]byte{2}, 0, 1, "verified"),
+		newTxHistoryEntry(TX_TYPE_REWARD, DIRECTION_INBOUND, 1, [32]byte{1}, [32]byte{}, 10, 0, "verified"),
+		newTxHistoryEntry(TX_TYPE_REWARD, DIRECTION_INBOUND, 2, [32]byte{3}, [32]byte{}, 10, 0, "verified"),
+	}
+
+	ids := make(map[string]bool)
+	for _, entry := range entries {
+		if ids[entry.Id] {
+			t.Errorf("duplicate id %v", entry.Id)
+		}
+		ids[entry.Id] = true
+	}
+
+	//The id of a tx does not depend on its block.
+	pending := newTxHistoryEntry(TX_TYPE_FUNDS, DIRECTION_INBOUND, 0, [32]byte{}, [32]byte{2}, 1, 1, "not verified")
+	if pending.Id != entries[0].Id {
+		t.Errorf("got id %v for the pending tx, want %v", pending.Id, entries[0].Id)
+	}
+}
+
+func TestCacheHistory(t *testing.T) {
+	defer func() { historyCache = make(map[[32]byte]*cachedHistory) }()
+
+	historyCache = make(map[[32]byte]*cachedHistory)
+	start := time.Now()
+
+	//The cache is filled with histories of the current chain, the first one is used last.
+	for i := 0; i < util.HISTORY_CACHE_SIZE; i++ {
+		cacheHistory([32]byte{byte(i)}, &cachedHistory{lastHeader: [32]byte{1}, usedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+	historyCache[[32]byte{0}].usedAt = start.Add(time.Hour)
+
+	cacheHistory([32]byte{0xff}, &cachedHistory{lastHeader: [32]byte{1}, usedAt: start.Add(2 * time.Hour)})
+
+	if len(historyCache) != util.HISTORY_CACHE_SIZE {
+		t.Errorf("got %v cached histories, want %v", len(historyCache), util.HISTORY_CACHE_SIZE)
+	}
+
+	if historyCache[[32]byte{0}] == nil || historyCache[[32]byte{1}] != nil || historyCache[[32]byte{0xff}] == nil {
+		t.Errorf("the least recently used history is not dropped")
+	}
+
+	//A history of a newer chain drops all histories of older chains.
+	cacheHistory([32]byte{0}, &cachedHistory{lastHeader: [32]byte{2}, usedAt: start})
+
+	if len(historyCache) != 1 || historyCache[[32]byte{0}].lastHeader != [32]byte{2} {
+		t.Errorf("got %v cached histories", len(historyCache))
+	}
+}
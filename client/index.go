@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+)
+
+var (
+	//Serializes reads and writes of the transaction index.
+	indexMutex = &sync.Mutex{}
+)
+
+//Fetch the transactions of the block which are relevant for the given address hash, validate them and add them to the
+//index of every party, see txParties. ConfigTxs are only fetched for the config index and the block's beneficiary.
+func indexBlock(ctx context.Context, pubKeyHash [32]byte, block *protocol.Block) (indexedTxs []*cstorage.IndexedTx, err error) {
+	for _, txHash := range block.FundsTxData {
+		tx, err := network.GetTx(ctx, p2p.FUNDSTX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+		fundsTx := tx.(*protocol.FundsTx)
+
+		if fundsTx.From == pubKeyHash || fundsTx.To == pubKeyHash || block.Beneficiary == pubKeyHash {
+			//Validate tx
+			if err := validateTx(ctx, block, tx, txHash); err != nil {
+				return nil, err
+			}
+
+			indexedTxs = append(indexedTxs, &cstorage.IndexedTx{BlockHash: block.Hash, Height: block.Height, TxHash: txHash, Tx: tx})
+		}
+	}
+
+	//Check if Account was issued and collect fee
+	for _, txHash := range block.AccTxData {
+		tx, err := network.GetTx(ctx, p2p.ACCTX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+		accTx := tx.(*protocol.AccTx)
+
+		if protocol.SerializeHashContent(accTx.PubKey) == pubKeyHash || block.Beneficiary == pubKeyHash {
+			//Validate tx
+			if err := validateTx(ctx, block, tx, txHash); err != nil {
+				return nil, err
+			}
+
+			indexedTxs = append(indexedTxs, &cstorage.IndexedTx{BlockHash: block.Hash, Height: block.Height, TxHash: txHash, Tx: tx})
+		}
+	}
+
+	for _, txHash := range block.ConfigTxData {
+		if pubKeyHash != configIndexAddress && block.Beneficiary != pubKeyHash {
+			break
+		}
+
+		tx, err := network.GetTx(ctx, p2p.CONFIGTX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		//Validate tx
+		if err := validateTx(ctx, block, tx, txHash); err != nil {
+			return nil, err
+		}
+
+		indexedTxs = append(indexedTxs, &cstorage.IndexedTx{BlockHash: block.Hash, Height: block.Height, TxHash: txHash, Tx: tx})
+	}
+
+	for _, txHash := range block.StakeTxData {
+		tx, err := network.GetTx(ctx, p2p.STAKETX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+		stakeTx := tx.(*protocol.StakeTx)
+
+		if stakeTx.Account == pubKeyHash || block.Beneficiary == pubKeyHash {
+			//Validate tx
+			if err := validateTx(ctx, block, tx, txHash); err != nil {
+				return nil, err
+			}
+
+			indexedTxs = append(indexedTxs, &cstorage.IndexedTx{BlockHash: block.Hash, Height: block.Height, TxHash: txHash, Tx: tx})
+		}
+	}
+
+	for _, indexedTx := range indexedTxs {
+		for _, addressHash := range txParties(indexedTx.Tx, block.Beneficiary) {
+			if err := cstorage.WriteIndexedTx(addressHash, indexedTx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return indexedTxs, nil
+}
+
+//Returns the address hashes a tx is indexed under: its sender and recipient and the beneficiary who collects the fee.
+//ConfigTxs are indexed under configIndexAddress instead of a sender.
+func txParties(tx protocol.Transaction, beneficiary [32]byte) (parties [][32]byte) {
+	switch tx := tx.(type) {
+	case *protocol.FundsTx:
+		parties = append(parties, tx.From, tx.To)
+	case *protocol.AccTx:
+		parties = append(parties, protocol.SerializeHashContent(tx.PubKey))
+	case *protocol.ConfigTx:
+		parties = append(parties, configIndexAddress)
+	case *protocol.StakeTx:
+		parties = append(parties, tx.Account)
+	}
+
+	parties = append(parties, beneficiary)
+
+	//Remove duplicates, e.g. a tx to oneself.
+	var unique [][32]byte
+	seen := make(map[[32]byte]bool)
+	for _, party := range parties {
+		if !seen[party] {
+			seen[party] = true
+			unique = append(unique, party)
+		}
+	}
+
+	return unique
+}
+
+//Index an incoming block header for all addresses whose index reaches up to the header's predecessor. Addresses which
+//lag behind are caught up with the next state query.
+func indexBlockHeader(blockHeader *protocol.Block) {
+	ctx := context.Background()
+
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	for _, addressHash := range cstorage.ReadIndexedAddresses() {
+		if indexHeight, _ := cstorage.ReadIndexHeight(addressHash); indexHeight+1 != blockHeader.Height {
+			continue
+		}
+
+		if isRelevantBlockHeader(blockHeader, addressHash) {
+			relevantBlocks, err := getRelevantBlocks(ctx, []*protocol.Block{blockHeader})
+			if err != nil {
+				logger.Printf("Indexing block %x failed: %v\n", blockHeader.Hash[:8], err)
+				continue
+			}
+
+			//The index height must not pass a block which was not indexed.
+			if relevantBlocks[0] == nil {
+				logger.Printf("Indexing block %x failed: block not available\n", blockHeader.Hash[:8])
+				continue
+			}
+
+			if _, err := indexBlock(ctx, addressHash, relevantBlocks[0]); err != nil {
+				logger.Printf("Indexing block %x failed: %v\n", blockHeader.Hash[:8], err)
+				continue
+			}
+		}
+
+		cstorage.WriteIndexHeight(addressHash, blockHeader.Height)
+	}
+}
+
+//Returns the height the index reaches after indexing the given blocks of the headers: the tip height, or the height
+//before the first block which is not available. ok is false if not even the genesis block is indexed.
+func indexedHeight(headers []*protocol.Block, blocks []*protocol.Block, tipHeight uint32) (height uint32, ok bool) {
+	for i, block := range blocks {
+		if block == nil {
+			if headers[i].Height == 0 {
+				return 0, false
+			}
+
+			return headers[i].Height - 1, true
+		}
+	}
+
+	return tipHeight, true
+}
+
+//Remove all indexed transactions of blocks above the given height, because these blocks are rolled back. Returns the
+//hashes of the removed transactions.
+func rollbackIndex(height uint32) [][32]byte {
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	return cstorage.DeleteIndexAbove(height)
+}
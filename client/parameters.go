@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+//The zero hash is no account's address hash. indexBlock indexes every ConfigTx under it, see txParties.
+var configIndexAddress [32]byte
+
+//Returns the verified ConfigTxs of the chain, ordered by height. Blocks up to the index height are read from the index,
+//younger blocks are fetched from the network.
+func getConfigHistory(ctx context.Context) (configTxs []*cstorage.IndexedTx, err error) {
+	headers := getBlockHeaders()
+
+	chain := make(map[[32]byte]bool)
+	var configHeaders []*protocol.Block
+	for _, blockHeader := range headers {
+		chain[blockHeader.Hash] = true
+		if blockHeader.NrConfigTx > 0 {
+			configHeaders = append(configHeaders, blockHeader)
+		}
+	}
+
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	indexHeight, indexed := cstorage.ReadIndexHeight(configIndexAddress)
+	if indexed {
+		for _, indexedTx := range cstorage.ReadIndexedTxs(configIndexAddress) {
+			//Entries above the index height stem from an aborted run and are indexed again below.
+			if indexedTx.Height <= indexHeight && chain[indexedTx.BlockHash] {
+				configTxs = append(configTxs, indexedTx)
+			}
+		}
+	}
+
+	var unindexedHeaders []*protocol.Block
+	for _, blockHeader := range configHeaders {
+		if !indexed || blockHeader.Height > indexHeight {
+			unindexedHeaders = append(unindexedHeaders, blockHeader)
+		}
+	}
+
+	blocks, err := getRelevantBlocks(ctx, unindexedHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+
+		indexedTxs, err := indexBlock(ctx, configIndexAddress, block)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, indexedTx := range indexedTxs {
+			if _, ok := indexedTx.Tx.(*protocol.ConfigTx); ok {
+				configTxs = append(configTxs, indexedTx)
+			}
+		}
+	}
+
+	if len(headers) > 0 {
+		if height, ok := indexedHeight(unindexedHeaders, blocks, headers[len(headers)-1].Height); ok {
+			cstorage.WriteIndexHeight(configIndexAddress, height)
+		}
+	}
+
+	return configTxs, nil
+}
+
+type NetworkParameters struct {
+	BlockSize     uint64          `json:"blockSize"`
+	DiffInterval  uint64          `json:"diffInterval"`
+	FeeMinimum    uint64          `json:"feeMinimum"`
+	BlockInterval uint64          `json:"blockInterval"`
+	BlockReward   uint64          `json:"blockReward"`
+	Height        uint32          `json:"height"`
+	History       []*ConfigChange `json:"history"`
+}
+
+//A ConfigTx of the chain. Applied is false if the miners rejected the payload, e.g. because it is out of bounds.
+type ConfigChange struct {
+	Height    uint32 `json:"height"`
+	BlockHash string `json:"blockHash"`
+	TxHash    string `json:"txHash"`
+	Id        uint8  `json:"id"`
+	Parameter string `json:"parameter"`
+	Payload   uint64 `json:"payload"`
+	Applied   bool   `json:"applied"`
+}
+
+//The ids of the ConfigTxs, as set by the network command.
+var parameterNames = map[uint8]string{
+	1: "blockSize",
+	2: "diffInterval",
+	3: "feeMinimum",
+	4: "blockInterval",
+	5: "blockReward",
+}
+
+//Returns the parameters of the synced chain and the ConfigTxs which changed them, from the oldest to the youngest.
+func GetNetworkParameters(ctx context.Context) (*NetworkParameters, error) {
+	parameters, history, err := getNetworkParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networkParameters := &NetworkParameters{
+		BlockSize:     parameters.Block_size,
+		DiffInterval:  parameters.Diff_interval,
+		FeeMinimum:    parameters.Fee_minimum,
+		BlockInterval: parameters.Block_interval,
+		BlockReward:   parameters.Block_reward,
+		History:       history,
+	}
+
+	if last := getLastBlockHeader(); last != nil {
+		networkParameters.Height = last.Height
+	}
+
+	return networkParameters, nil
+}
+
+//Replays the ConfigTxs of the chain on top of the default parameters.
+func getNetworkParameters(ctx context.Context) (parameters miner.Parameters, history []*ConfigChange, err error) {
+	configTxs, err := getConfigHistory(ctx)
+	if err != nil {
+		return parameters, nil, err
+	}
+
+	parameters = miner.NewDefaultParameters()
+	for _, indexedTx := range configTxs {
+		tx, ok := indexedTx.Tx.(*protocol.ConfigTx)
+		if !ok {
+			continue
+		}
+
+		parameter, known := parameterNames[tx.Id]
+		if !known {
+			parameter = "unknown"
+		}
+
+		configTxSlice := []*protocol.ConfigTx{tx}
+		history = append(history, &ConfigChange{
+			Height:    indexedTx.Height,
+			BlockHash: hex.EncodeToString(indexedTx.BlockHash[:]),
+			TxHash:    hex.EncodeToString(indexedTx.TxHash[:]),
+			Id:        tx.Id,
+			Parameter: parameter,
+			Payload:   tx.Payload,
+			Applied:   miner.CheckAndChangeParameters(&parameters, &configTxSlice),
+		})
+	}
+
+	return parameters, history, nil
+}
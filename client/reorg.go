@@ -0,0 +1,40 @@
+package client
+
+import "sync"
+
+//Published whenever headers of the chain are replaced by a longer branch. The transactions of the orphaned blocks are
+//unconfirmed until they are included in the new branch.
+type ReorgEvent struct {
+	CommonAncestor [32]byte
+	AncestorHeight uint32
+	Orphaned       [][32]byte
+	UnconfirmedTxs [][32]byte
+}
+
+var (
+	reorgSubscribers []chan *ReorgEvent
+	reorgMutex       = &sync.Mutex{}
+)
+
+//Returns a channel which receives all following reorg events. Events are dropped if the subscriber does not keep up.
+func SubscribeReorgs() <-chan *ReorgEvent {
+	reorgMutex.Lock()
+	defer reorgMutex.Unlock()
+
+	ch := make(chan *ReorgEvent, 10)
+	reorgSubscribers = append(reorgSubscribers, ch)
+
+	return ch
+}
+
+func publishReorg(event *ReorgEvent) {
+	reorgMutex.Lock()
+	defer reorgMutex.Unlock()
+
+	for _, ch := range reorgSubscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -1,24 +1,27 @@
 package client
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/cstorage"
 	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/miner"
-	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+	"time"
 )
 
 var (
-	//All blockheaders of the whole chain
-	blockHeaders []*protocol.Block
+	//All blockheaders of the whole chain. Readers take a snapshot with getBlockHeaders, the slice is never modified in
+	//place.
+	blockHeaders      []*protocol.Block
+	blockHeadersMutex = &sync.RWMutex{}
 
-	activeParameters miner.Parameters
-
-	UnsignedAccTx    = make(map[[32]byte]*protocol.AccTx)
-	UnsignedConfigTx = make(map[[32]byte]*protocol.ConfigTx)
-	UnsignedFundsTx  = make(map[[32]byte]*protocol.FundsTx)
-	UnsignedIoTTx  = make(map[[32]byte]*protocol.IotTx)
+	//Serializes the writers of blockHeaders: loading, syncing and appending incoming headers.
+	syncMutex = &sync.Mutex{}
 
 	SignedIotTx = make(map[[32]byte]*protocol.IotTx)
 
@@ -26,136 +29,224 @@ var (
 
 //Update allBlockHeaders to the latest header. Start listening to broadcasted headers after.
 func Sync() {
+	if chainValidators == nil {
+		logger.Println("No genesis_validators configured, the beneficiaries of the block headers are not verified.")
+	}
+
+	LoadBlockHeaders()
+	go func() {
+		if err := SyncToNetwork(context.Background()); err != nil {
+			logger.Printf("Sync aborted: %v\n", err)
+		}
+
+		//The client is up to date with the network and can start listening for incoming headers.
+		network.Uptodate = true
+
+		incomingBlockHeaders()
+	}()
+}
+
+//Returns the headers of the chain, from the oldest to the youngest. The snapshot is not changed by later syncs.
+func getBlockHeaders() []*protocol.Block {
+	blockHeadersMutex.RLock()
+	defer blockHeadersMutex.RUnlock()
+
+	return blockHeaders
+}
+
+//Returns nil if no header is loaded.
+func getLastBlockHeader() *protocol.Block {
+	headers := getBlockHeaders()
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers[len(headers)-1]
+}
+
+//Called with syncMutex held.
+func setBlockHeaders(headers []*protocol.Block) {
+	blockHeadersMutex.Lock()
+	defer blockHeadersMutex.Unlock()
+
+	blockHeaders = headers
+}
+
+//Called with syncMutex held. Appending never overwrites headers of a snapshot, since snapshots end before the new
+//header.
+func appendBlockHeader(header *protocol.Block) {
+	blockHeadersMutex.Lock()
+	defer blockHeadersMutex.Unlock()
+
+	blockHeaders = append(blockHeaders, header)
+}
+
+//Called with syncMutex held. The kept headers are copied, so appending after the truncation does not overwrite the
+//orphaned headers of a snapshot.
+func truncateBlockHeaders(length int) {
+	blockHeadersMutex.Lock()
+	defer blockHeadersMutex.Unlock()
+
+	blockHeaders = append([]*protocol.Block{}, blockHeaders[:length]...)
+}
+
+func LoadBlockHeaders() {
+	syncMutex.Lock()
+	defer syncMutex.Unlock()
+
 	loadBlockHeaders()
-	go incomingBlockHeaders()
 }
 
+//Called with syncMutex held.
 func loadBlockHeaders() {
 	var last *protocol.Block
 
-	//youngest = fetchBlockHeader(nil)
 	if last = cstorage.ReadLastBlockHeader(); last != nil {
-		var loaded []*protocol.Block
-		loaded = loadDB(last, [32]byte{}, loaded)
-		blockHeaders = append(blockHeaders, loaded...)
-	}
+		loaded, err := loadDB(last, [32]byte{})
+		if err != nil {
+			//The headers are fetched from the network again with the next sync.
+			logger.Printf("Loading headers from DB failed: %v\n", err)
+			return
+		}
 
-	//The client is up to date with the network and can start listening for incoming headers.
-	network.Uptodate = true
+		setBlockHeaders(loaded)
+	}
 }
 
 func incomingBlockHeaders() {
 	for {
 		blockHeaderIn := <-network.BlockHeaderIn
 
-		var last *protocol.Block
-		var lastHash [32]byte
+		syncMutex.Lock()
+		processBlockHeader(blockHeaderIn)
+		syncMutex.Unlock()
+	}
+}
 
-		//Get the last header in the blockHeaders array. Its hash is relevant for appending the incoming header or the abort condition for recursive header fetching.
-		if len(blockHeaders) > 0 {
-			last = blockHeaders[len(blockHeaders)-1]
-			lastHash = last.Hash
-		} else {
-			lastHash = [32]byte{}
-		}
+//Called with syncMutex held.
+func processBlockHeader(blockHeaderIn *protocol.Block) {
+	var lastHash [32]byte
 
-		//The incoming block header is already the last saved in the array.
-		if blockHeaderIn.Hash == lastHash {
-			continue
-		}
+	//Get the last header of the chain. Its hash is relevant for appending the incoming header or the abort condition for header fetching.
+	last := getLastBlockHeader()
+	if last != nil {
+		lastHash = last.Hash
+	}
 
-		//The client is out of sync. Header cannot be appended to the array. The client must sync first.
-		if last == nil || blockHeaderIn.PrevHash != lastHash {
-			//Set the uptodate flag to false in order to avoid listening to new incoming block headers.
-			network.Uptodate = false
+	//The incoming block header is already the last saved in the array.
+	if blockHeaderIn.Hash == lastHash {
+		return
+	}
 
-			var loaded []*protocol.Block
+	//The client is out of sync. Header cannot be appended to the array. The client must sync first.
+	if last == nil || blockHeaderIn.PrevHash != lastHash {
+		//Set the uptodate flag to false in order to avoid listening to new incoming block headers.
+		network.Uptodate = false
 
-			if last == nil || len(blockHeaders) <= 100 {
-				blockHeaders = []*protocol.Block{}
-				loaded = loadNetwork(blockHeaderIn, [32]byte{}, loaded)
-			} else {
-				//Remove the last 100 headers. This is precaution if the array contains rolled back blocks.
-				blockHeaders = blockHeaders[:len(blockHeaders)-100]
-				loaded = loadNetwork(blockHeaderIn, blockHeaders[len(blockHeaders)-1].Hash, loaded)
-			}
+		if err := syncTo(context.Background(), blockHeaderIn); err != nil {
+			logger.Printf("Sync to header %x aborted: %v\n", blockHeaderIn.Hash[:8], err)
+		}
 
-			blockHeaders = append(blockHeaders, loaded...)
-			cstorage.WriteLastBlockHeader(blockHeaders[len(blockHeaders)-1])
+		network.Uptodate = true
+	} else {
+		ctx := context.Background()
 
-			network.Uptodate = true
-		} else if blockHeaderIn.PrevHash == lastHash {
-			saveAndLogBlockHeader(blockHeaderIn)
+		parameters, _, err := getNetworkParameters(ctx)
+		if err != nil {
+			logger.Printf("Incoming header rejected, parameters unknown: %v\n", err)
+			return
+		}
 
-			blockHeaders = append(blockHeaders, blockHeaderIn)
-			cstorage.WriteLastBlockHeader(blockHeaderIn)
+		if err := validateBlockHeader(ctx, blockHeaderIn, getBlockHeaders(), chainValidators, parameters); err != nil {
+			logger.Printf("Incoming header rejected: %v\n", err)
+			return
 		}
+
+		saveAndLogBlockHeader(blockHeaderIn)
+
+		appendBlockHeader(blockHeaderIn)
+		cstorage.WriteLastBlockHeader(blockHeaderIn)
+
+		go indexBlockHeader(blockHeaderIn)
+		notifyTxTracker()
 	}
 }
 
-func fetchBlockHeader(blockHash []byte) (blockHeader *protocol.Block) {
+func fetchBlockHeader(ctx context.Context, blockHash []byte) (blockHeader *protocol.Block) {
 	var errormsg string
 	if blockHash != nil {
 		errormsg = fmt.Sprintf("Loading header %x failed: ", blockHash[:8])
 	}
 
-	err := network.BlockHeaderReq(blockHash[:])
-	if err != nil {
-		logger.Println(errormsg + err.Error())
-		return nil
+	var err error
+	if blockHash != nil {
+		var hash [32]byte
+		copy(hash[:], blockHash)
+		blockHeader, err = network.GetBlockHeader(ctx, hash)
+	} else {
+		blockHeader, err = network.GetLatestBlockHeader(ctx)
 	}
 
-	blockHeaderI, err := network.Fetch(network.BlockHeaderChan)
 	if err != nil {
 		logger.Println(errormsg + err.Error())
 		return nil
 	}
 
-	blockHeader = blockHeaderI.(*protocol.Block)
-
 	logger.Printf("Fetch header with height %v\n", blockHeader.Height)
 
 	return blockHeader
 }
 
-func loadDB(last *protocol.Block, abort [32]byte, loaded []*protocol.Block) []*protocol.Block {
-	var ancestor *protocol.Block
+//Load the chain from the DB, walking back from the last header to the abort hash. The headers are returned from the
+//oldest to the youngest.
+func loadDB(last *protocol.Block, abort [32]byte) (loaded []*protocol.Block, err error) {
+	for current := last; ; {
+		logger.Printf("Header %x with height %v loaded from DB\n",
+			current.Hash[:8],
+			current.Height)
+
+		loaded = append(loaded, current)
 
-	if last.PrevHash != abort {
-		if ancestor = cstorage.ReadBlockHeader(last.PrevHash); ancestor == nil {
-			logger.Fatal()
+		if current.PrevHash == abort {
+			break
 		}
 
-		loaded = loadDB(ancestor, abort, loaded)
+		prevHash := current.PrevHash
+		if current = cstorage.ReadBlockHeader(prevHash); current == nil {
+			return nil, errors.New(fmt.Sprintf("header %x not found", prevHash[:8]))
+		}
 	}
 
-	logger.Printf("Header %x with height %v loaded from DB\n",
-		last.Hash[:8],
-		last.Height)
-
-	loaded = append(loaded, last)
+	for i, j := 0, len(loaded)-1; i < j; i, j = i+1, j-1 {
+		loaded[i], loaded[j] = loaded[j], loaded[i]
+	}
 
-	return loaded
+	return loaded, nil
 }
 
-func loadNetwork(last *protocol.Block, abort [32]byte, loaded []*protocol.Block) []*protocol.Block {
-	var ancestor *protocol.Block
-	if ancestor = fetchBlockHeader(last.PrevHash[:]); ancestor == nil {
-		for ancestor == nil {
-			logger.Printf("Try to fetch header %x with height %v again\n", last.Hash[:8], last.Height)
-			ancestor = fetchBlockHeader(last.PrevHash[:])
+//Fetch the predecessor of the given header. Retries with exponential backoff before it gives up or ctx is done.
+func fetchAncestor(ctx context.Context, blockHeader *protocol.Block) (ancestor *protocol.Block, err error) {
+	backoff := util.FETCH_BACKOFF * time.Second
+	for retry := 0; ; retry++ {
+		if ancestor = fetchBlockHeader(ctx, blockHeader.PrevHash[:]); ancestor != nil && ancestor.Hash == blockHeader.PrevHash {
+			return ancestor, nil
 		}
-	}
-
-	if last.PrevHash != abort {
-		loaded = loadNetwork(ancestor, abort, loaded)
-	}
 
-	saveAndLogBlockHeader(last)
+		if retry == util.FETCH_RETRIES {
+			return nil, errors.New(fmt.Sprintf("fetching header %x failed %v times", blockHeader.PrevHash[:8], retry+1))
+		}
 
-	loaded = append(loaded, last)
+		logger.Printf("Try to fetch header %x with height %v again in %v\n", blockHeader.PrevHash[:8], blockHeader.Height-1, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 
-	return loaded
+		if backoff *= 2; backoff > util.FETCH_MAX_BACKOFF*time.Second {
+			backoff = util.FETCH_MAX_BACKOFF * time.Second
+		}
+	}
 }
 
 func saveAndLogBlockHeader(blockHeader *protocol.Block) {
@@ -165,7 +256,7 @@ func saveAndLogBlockHeader(blockHeader *protocol.Block) {
 		blockHeader.Height)
 }
 
-func getState(acc *Account, lastTenTx []*FundsTxJson) (err error) {
+func getState(ctx context.Context, acc *Account) (history []*TxHistoryEntry, err error) {
 	pubKeyHash := protocol.SerializeHashContent(acc.Address)
 	//Get blocks if the Acc address:
 	//* got issued as an Acc
@@ -174,130 +265,189 @@ func getState(acc *Account, lastTenTx []*FundsTxJson) (err error) {
 	//* is block's beneficiary
 	//* nr of configTx in block is > 0 (in order to maintain params in light-client)
 
-	relevantHeadersBeneficiary, relevantHeadersConfigBF := getRelevantBlockHeaders(pubKeyHash)
+	headers := getBlockHeaders()
 
-	acc.Balance += activeParameters.Block_reward * uint64(len(relevantHeadersBeneficiary))
+	var tipHeight uint32
+	if len(headers) > 0 {
+		tipHeight = headers[len(headers)-1].Height
+	}
 
-	relevantBlocks, err := getRelevantBlocks(relevantHeadersConfigBF)
-	for _, block := range relevantBlocks {
-		if block != nil {
-			//Balance funds and collect fee
-			for _, txHash := range block.FundsTxData {
-				err := network.TxReq(p2p.FUNDSTX_REQ, txHash)
-				if err != nil {
-					return err
-				}
+	relevantHeadersBeneficiary, relevantHeadersConfigBF := getRelevantBlockHeaders(headers, pubKeyHash)
 
-				txI, err := network.Fetch(network.FundsTxChan)
-				if err != nil {
-					return err
-				}
+	//The parameters are local to the call, concurrent calls do not share them.
+	parameters := miner.NewDefaultParameters()
+	acc.Balance += parameters.Block_reward * uint64(len(relevantHeadersBeneficiary))
 
-				tx := txI.(protocol.Transaction)
-				fundsTx := txI.(*protocol.FundsTx)
+	for _, blockHeader := range relevantHeadersBeneficiary {
+		history = append(history, newTxHistoryEntry(TX_TYPE_REWARD, DIRECTION_INBOUND, blockHeader.Height, blockHeader.Hash, [32]byte{}, parameters.Block_reward, 0, "verified"))
+	}
+
+	//ConfigTxs are indexed under configIndexAddress, see txParties.
+	configTxs, err := getConfigHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-				if fundsTx.From == pubKeyHash || fundsTx.To == pubKeyHash || block.Beneficiary == pubKeyHash {
-					//Validate tx
-					if err := validateTx(block, tx, txHash); err != nil {
-						return err
-					}
+	indexMutex.Lock()
+	defer indexMutex.Unlock()
+
+	//Transactions of blocks up to the index height have been verified before and are read from the index. Only younger
+	//blocks are fetched from the network.
+	indexHeight, indexed := cstorage.ReadIndexHeight(pubKeyHash)
+
+	relevantHeaders := make(map[[32]byte]*protocol.Block)
+	var unindexedHeaders []*protocol.Block
+	for _, blockHeader := range relevantHeadersConfigBF {
+		relevantHeaders[blockHeader.Hash] = blockHeader
+		if !indexed || blockHeader.Height > indexHeight {
+			unindexedHeaders = append(unindexedHeaders, blockHeader)
+		}
+	}
+
+	var relevantTxs []*cstorage.IndexedTx
+	if indexed {
+		for _, indexedTx := range cstorage.ReadIndexedTxs(pubKeyHash) {
+			//Entries above the index height stem from an aborted run and are indexed again below.
+			if indexedTx.Height <= indexHeight {
+				relevantTxs = append(relevantTxs, indexedTx)
+			}
+		}
+	}
+
+	relevantBlocks, err := getRelevantBlocks(ctx, unindexedHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, block := range relevantBlocks {
+		if block == nil {
+			logger.Printf("Block %x is not available, its transactions are missing in the state\n", unindexedHeaders[i].Hash[:8])
+			continue
+		}
+
+		indexedTxs, err := indexBlock(ctx, pubKeyHash, block)
+		if err != nil {
+			return nil, err
+		}
 
-					if fundsTx.From == pubKeyHash {
-						//If Acc is no root, balance funds
-						if !acc.IsRoot {
-							acc.Balance -= fundsTx.Amount
-							acc.Balance -= fundsTx.Fee
-						}
+		relevantTxs = append(relevantTxs, indexedTxs...)
+	}
 
-						acc.TxCnt += 1
-					}
+	//Blocks after a missing block are indexed again with the next query.
+	if len(headers) > 0 {
+		if height, ok := indexedHeight(unindexedHeaders, relevantBlocks, tipHeight); ok {
+			cstorage.WriteIndexHeight(pubKeyHash, height)
+		}
+	}
 
-					if fundsTx.To == pubKeyHash {
-						acc.Balance += fundsTx.Amount
+	relevantTxs = append(relevantTxs, configTxs...)
 
-						put(lastTenTx, ConvertFundsTx(fundsTx, "verified"))
-					}
+	applied := make(map[[64]byte]bool)
+	for _, indexedTx := range relevantTxs {
+		//The block is not part of the chain anymore.
+		block := relevantHeaders[indexedTx.BlockHash]
+		if block == nil {
+			continue
+		}
 
-					if block.Beneficiary == pubKeyHash {
-						acc.Balance += fundsTx.Fee
-					}
+		//A ConfigTx of a block the account mined is indexed under both addresses.
+		var key [64]byte
+		copy(key[:32], indexedTx.BlockHash[:])
+		copy(key[32:], indexedTx.TxHash[:])
+		if applied[key] {
+			continue
+		}
+		applied[key] = true
+
+		switch tx := indexedTx.Tx.(type) {
+		//Balance funds and collect fee
+		case *protocol.FundsTx:
+			if tx.From == pubKeyHash {
+				//If Acc is no root, balance funds
+				if !acc.IsRoot {
+					acc.Balance -= tx.Amount
+					acc.Balance -= tx.Fee
 				}
+
+				acc.TxCnt += 1
+
+				history = append(history, newFundsTxHistoryEntry(tx, DIRECTION_OUTBOUND, block, "verified"))
 			}
 
-			//Check if Account was issued and collect fee
-			//for _, txHash := range block.AccTxData {
-			//	err := network.TxReq(p2p.ACCTX_REQ, txHash)
-			//	if err != nil {
-			//		return err
-			//	}
-			//
-			//	txI, err := network.Fetch(network.AccTxChan)
-			//	if err != nil {
-			//		return err
-			//	}
-			//
-			//	tx := txI.(protocol.Transaction)
-			//	accTx := txI.(*protocol.AccTx)
-			//
-			//	if accTx.PubKey == acc.Address || block.Beneficiary == pubKeyHash {
-			//		//Validate tx
-			//		if err := validateTx(block, tx, txHash); err != nil {
-			//			return err
-			//		}
-			//
-			//		if accTx.PubKey == acc.Address {
-			//			acc.IsCreated = true
-			//		}
-			//
-			//		if block.Beneficiary == pubKeyHash {
-			//			acc.Balance += accTx.Fee
-			//		}
-			//	}
-			//}
-
-			//Update config parameters and collect fee
-			for _, txHash := range block.ConfigTxData {
-				err := network.TxReq(p2p.CONFIGTX_REQ, txHash)
-				if err != nil {
-					return err
-				}
+			if tx.To == pubKeyHash {
+				acc.Balance += tx.Amount
 
-				txI, err := network.Fetch(network.ConfigTxChan)
-				if err != nil {
-					return err
-				}
+				history = append(history, newFundsTxHistoryEntry(tx, DIRECTION_INBOUND, block, "verified"))
+			}
 
-				tx := txI.(protocol.Transaction)
-				configTx := txI.(*protocol.ConfigTx)
+			if block.Beneficiary == pubKeyHash {
+				acc.Balance += tx.Fee
 
-				configTxSlice := []*protocol.ConfigTx{configTx}
+				history = append(history, newFundsTxHistoryEntry(tx, DIRECTION_FEE, block, "verified"))
+			}
 
-				if block.Beneficiary == pubKeyHash {
-					//Validate tx
-					if err := validateTx(block, tx, txHash); err != nil {
-						return err
-					}
+		//Check if Account was issued and collect fee
+		case *protocol.AccTx:
+			if protocol.SerializeHashContent(tx.PubKey) == pubKeyHash {
+				acc.IsCreated = true
+				acc.CreatedAtHeight = block.Height
+				acc.CreatedInBlock = hex.EncodeToString(block.Hash[:])
+				acc.Issuer = hex.EncodeToString(tx.Issuer[:])
 
-					acc.Balance += configTx.Fee
+				history = append(history, newTxHistoryEntry(TX_TYPE_ACC, DIRECTION_INBOUND, block.Height, block.Hash, indexedTx.TxHash, 0, tx.Fee, "verified"))
+			}
+
+			if block.Beneficiary == pubKeyHash {
+				acc.Balance += tx.Fee
+
+				history = append(history, newTxHistoryEntry(TX_TYPE_ACC, DIRECTION_FEE, block.Height, block.Hash, indexedTx.TxHash, 0, tx.Fee, "verified"))
+			}
+
+		//Update config parameters and collect fee
+		case *protocol.ConfigTx:
+			configTxSlice := []*protocol.ConfigTx{tx}
+
+			if block.Beneficiary == pubKeyHash {
+				acc.Balance += tx.Fee
+
+				history = append(history, newTxHistoryEntry(TX_TYPE_CONFIG, DIRECTION_FEE, block.Height, block.Hash, indexedTx.TxHash, 0, tx.Fee, "verified"))
+			}
+
+			miner.CheckAndChangeParameters(&parameters, &configTxSlice)
+
+		//Update the staking state and collect fee
+		case *protocol.StakeTx:
+			if tx.Account == pubKeyHash {
+				if !acc.IsRoot {
+					acc.Balance -= tx.Fee
 				}
 
-				miner.CheckAndChangeParameters(&activeParameters, &configTxSlice)
+				acc.IsStaking = tx.IsStaking
+
+				history = append(history, newTxHistoryEntry(TX_TYPE_STAKE, DIRECTION_OUTBOUND, block.Height, block.Hash, indexedTx.TxHash, 0, tx.Fee, "verified"))
 			}
 
-			//TODO stakeTx
+			if block.Beneficiary == pubKeyHash {
+				acc.Balance += tx.Fee
 
+				history = append(history, newTxHistoryEntry(TX_TYPE_STAKE, DIRECTION_FEE, block.Height, block.Hash, indexedTx.TxHash, 0, tx.Fee, "verified"))
+			}
 		}
 	}
 
 	addressHash := protocol.SerializeHashContent(acc.Address)
-	for _, tx := range network.NonVerifiedTxReq(addressHash) {
+	for _, tx := range network.NonVerifiedTxReq(ctx, addressHash) {
 		if tx.To == addressHash {
-			put(lastTenTx, ConvertFundsTx(tx, "not verified"))
+			history = append(history, newFundsTxHistoryEntry(tx, DIRECTION_INBOUND, nil, "not verified"))
 		}
 		if tx.From == addressHash {
 			acc.TxCnt++
+
+			history = append(history, newFundsTxHistoryEntry(tx, DIRECTION_OUTBOUND, nil, "not verified"))
 		}
 	}
 
-	return nil
+	sortHistory(history)
+
+	return history, nil
 }
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestBlockHeadersSnapshot(t *testing.T) {
+	defer setBlockHeaders(nil)
+
+	headers := make([]*protocol.Block, 0, 8)
+	for height := uint32(0); height < 4; height++ {
+		headers = append(headers, &protocol.Block{Height: height, Hash: [32]byte{byte(height)}})
+	}
+	setBlockHeaders(headers)
+
+	snapshot := getBlockHeaders()
+
+	//A reorg replaces the last two headers.
+	truncateBlockHeaders(2)
+	appendBlockHeader(&protocol.Block{Height: 2, Hash: [32]byte{0xf2}})
+	appendBlockHeader(&protocol.Block{Height: 3, Hash: [32]byte{0xf3}})
+	appendBlockHeader(&protocol.Block{Height: 4, Hash: [32]byte{0xf4}})
+
+	if len(snapshot) != 4 {
+		t.Fatalf("snapshot length: got %v, want 4", len(snapshot))
+	}
+
+	for i, header := range snapshot {
+		if header.Hash != [32]byte{byte(i)} {
+			t.Errorf("snapshot header %v: got %x, want %x", i, header.Hash[:1], []byte{byte(i)})
+		}
+	}
+
+	if last := getLastBlockHeader(); last.Hash != [32]byte{0xf4} {
+		t.Errorf("last header: got %x, want f4", last.Hash[:1])
+	}
+}
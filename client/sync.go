@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+	"time"
+)
+
+const (
+	SYNC_PHASE_FETCHING = "fetching"
+	SYNC_PHASE_APPLYING = "applying"
+)
+
+type SyncStatus struct {
+	Syncing      bool   `json:"syncing"`
+	Phase        string `json:"phase,omitempty"`
+	Height       uint32 `json:"height"`
+	TargetHeight uint32 `json:"targetHeight"`
+	Done         uint32 `json:"done"`
+	Total        uint32 `json:"total"`
+	EtaSeconds   int64  `json:"etaSeconds"`
+}
+
+var (
+	syncStatus       SyncStatus
+	syncPhaseStarted time.Time
+	syncStatusMutex  = &sync.Mutex{}
+)
+
+func GetSyncStatus() SyncStatus {
+	syncStatusMutex.Lock()
+	defer syncStatusMutex.Unlock()
+
+	status := syncStatus
+	if last := getLastBlockHeader(); last != nil {
+		status.Height = last.Height
+	}
+
+	//The remaining time is extrapolated from the progress of the current phase.
+	if status.Syncing && status.Done > 0 && status.Total > status.Done {
+		elapsed := time.Since(syncPhaseStarted)
+		status.EtaSeconds = int64(elapsed.Seconds() / float64(status.Done) * float64(status.Total-status.Done))
+	}
+
+	return status
+}
+
+func setSyncPhase(phase string, targetHeight uint32, total uint32) {
+	syncStatusMutex.Lock()
+	defer syncStatusMutex.Unlock()
+
+	syncStatus = SyncStatus{Syncing: true, Phase: phase, TargetHeight: targetHeight, Total: total}
+	syncPhaseStarted = time.Now()
+}
+
+func setSyncProgress(done uint32) {
+	syncStatusMutex.Lock()
+	defer syncStatusMutex.Unlock()
+
+	syncStatus.Done = done
+}
+
+func finishSync() {
+	syncStatusMutex.Lock()
+	defer syncStatusMutex.Unlock()
+
+	syncStatus = SyncStatus{}
+}
+
+//Sync the chain to the latest header of the network. The sync is aborted once ctx is done, it resumes with the next
+//sync.
+func SyncToNetwork(ctx context.Context) error {
+	syncMutex.Lock()
+	defer syncMutex.Unlock()
+
+	target := fetchBlockHeader(ctx, nil)
+	if target == nil {
+		return errors.New("latest header not received")
+	}
+
+	return syncTo(ctx, target)
+}
+
+//Sync the chain to the target header. The headers are fetched backwards from the target until the common ancestor with
+//the chain is found. Fetched headers are kept in the DB, so an interrupted sync resumes where it stopped. If the
+//target's branch forks off the chain, only the headers after the common ancestor are replaced. Called with syncMutex
+//held.
+func syncTo(ctx context.Context, target *protocol.Block) error {
+	defer finishSync()
+
+	headers := getBlockHeaders()
+
+	chainIndex := make(map[[32]byte]int)
+	for i, blockHeader := range headers {
+		chainIndex[blockHeader.Hash] = i
+	}
+
+	//The target is already part of the chain.
+	if _, exists := chainIndex[target.Hash]; exists {
+		return nil
+	}
+
+	var startHeight uint32
+	if len(headers) > 0 {
+		last := headers[len(headers)-1]
+
+		//Only switch to a longer branch.
+		if target.Height <= last.Height {
+			return errors.New(fmt.Sprintf("branch with height %v is not longer than the chain with height %v", target.Height, last.Height))
+		}
+
+		startHeight = last.Height
+	}
+
+	setSyncPhase(SYNC_PHASE_FETCHING, target.Height, target.Height-startHeight)
+
+	if err := cstorage.WriteSyncHeader(target); err != nil {
+		return err
+	}
+
+	//The branch is collected from the youngest to the oldest header. It ends before the common ancestor.
+	branch := [][32]byte{target.Hash}
+	ancestorIndex := -1
+	for oldest := target; ; {
+		if i, exists := chainIndex[oldest.PrevHash]; exists {
+			ancestorIndex = i
+			break
+		}
+
+		//The branch has no common ancestor with the chain. The whole chain is replaced.
+		if oldest.PrevHash == [32]byte{} {
+			break
+		}
+
+		//Headers fetched by an interrupted sync are not fetched again.
+		ancestor := cstorage.ReadSyncHeader(oldest.PrevHash)
+		if ancestor == nil {
+			var err error
+			if ancestor, err = fetchAncestor(ctx, oldest); err != nil {
+				return err
+			}
+
+			if err := cstorage.WriteSyncHeader(ancestor); err != nil {
+				return err
+			}
+		}
+
+		branch = append(branch, ancestor.Hash)
+		oldest = ancestor
+
+		setSyncProgress(uint32(len(branch)))
+	}
+
+	var ancestor *protocol.Block
+	if ancestorIndex >= 0 {
+		ancestor = headers[ancestorIndex]
+	}
+
+	orphaned := headers[ancestorIndex+1:]
+
+	//ConfigTxs of the branch apply to the validation from the next sync on.
+	parameters, _, err := getNetworkParameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	//The chain is only reorganized if the whole branch is valid. The branch is validated on top of the chain up to the
+	//common ancestor, the capacity is limited so appending copies the headers instead of overwriting the chain's.
+	var validated []*protocol.Block
+	if len(orphaned) > 0 {
+		chain := headers[:ancestorIndex+1 : ancestorIndex+1]
+		for i := len(branch) - 1; i >= 0; i-- {
+			header := cstorage.ReadSyncHeader(branch[i])
+			if err := validateSyncHeader(ctx, branch[i], header, chain, parameters); err != nil {
+				return err
+			}
+
+			chain = append(chain, header)
+			validated = append(validated, header)
+		}
+	}
+
+	setSyncPhase(SYNC_PHASE_APPLYING, target.Height, uint32(len(branch)))
+
+	event := &ReorgEvent{}
+	if ancestor != nil {
+		event.CommonAncestor = ancestor.Hash
+		event.AncestorHeight = ancestor.Height
+	}
+
+	if len(orphaned) > 0 {
+		for _, orphan := range orphaned {
+			cstorage.DeleteBlockHeader(orphan.Hash)
+			event.Orphaned = append(event.Orphaned, orphan.Hash)
+		}
+
+		event.UnconfirmedTxs = rollbackIndex(event.AncestorHeight)
+		truncateBlockHeaders(ancestorIndex + 1)
+		if ancestor != nil {
+			cstorage.WriteLastBlockHeader(ancestor)
+		}
+
+		logger.Printf("Reorg: %v headers after %x with height %v orphaned, %v indexed transactions unconfirmed\n",
+			len(event.Orphaned), event.CommonAncestor[:8], event.AncestorHeight, len(event.UnconfirmedTxs))
+
+		publishReorg(event)
+	}
+
+	//Headers are appended one by one, so the chain is consistent if the sync is interrupted. If a header is invalid,
+	//the valid headers before it are kept.
+	for i := len(branch) - 1; i >= 0; i-- {
+		var header *protocol.Block
+		if validated != nil {
+			header = validated[len(branch)-1-i]
+		} else {
+			header = cstorage.ReadSyncHeader(branch[i])
+			if err := validateSyncHeader(ctx, branch[i], header, getBlockHeaders(), parameters); err != nil {
+				return err
+			}
+		}
+
+		saveAndLogBlockHeader(header)
+		appendBlockHeader(header)
+		cstorage.WriteLastBlockHeader(header)
+
+		setSyncProgress(uint32(len(branch) - i))
+		notifyTxTracker()
+	}
+
+	cstorage.DeleteSyncHeaders()
+
+	return nil
+}
+
+//Validate the header against the chain preceding it. An invalid branch is discarded, it is not resumed by the next
+//sync.
+func validateSyncHeader(ctx context.Context, hash [32]byte, header *protocol.Block, chain []*protocol.Block, parameters miner.Parameters) error {
+	if header == nil {
+		cstorage.DeleteSyncHeaders()
+		return errors.New(fmt.Sprintf("header %x not found in DB", hash[:8]))
+	}
+
+	if err := validateBlockHeader(ctx, header, chain, chainValidators, parameters); err != nil {
+		//If the validity is open, e.g. because a block could not be fetched, the branch is validated again with the
+		//next sync.
+		var validationErr *HeaderValidationError
+		if errors.As(err, &validationErr) {
+			cstorage.DeleteSyncHeaders()
+		}
+
+		return err
+	}
+
+	return nil
+}
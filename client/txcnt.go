@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+	"time"
+)
+
+var txCntMutex = &sync.Mutex{}
+
+//Returns the TxCnt for the account's next tx. It follows the account's verified and pending txs and the TxCnts reserved
+//by earlier calls. The returned TxCnt stays reserved until it is part of the account's state or util.TXCNT_RESERVATION
+//seconds passed, so quick successive sends do not collide. Call ReleaseTxCnt if the tx is not sent.
+func ReserveTxCnt(ctx context.Context, address [32]byte) (uint32, error) {
+	acc, _, err := GetAccount(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+
+	addressHash := protocol.SerializeHashContent(address)
+
+	txCntMutex.Lock()
+	defer txCntMutex.Unlock()
+
+	reservations := cstorage.ReadTxCntReservations(addressHash)
+	for txCnt, reservedAt := range reservations {
+		if txCnt < acc.TxCnt || time.Since(time.Unix(reservedAt, 0)) > util.TXCNT_RESERVATION*time.Second {
+			cstorage.DeleteTxCntReservation(addressHash, txCnt)
+			delete(reservations, txCnt)
+		}
+	}
+
+	//A released TxCnt is handed out again, so no gap remains.
+	txCnt := acc.TxCnt
+	for {
+		if _, reserved := reservations[txCnt]; !reserved {
+			break
+		}
+
+		txCnt++
+	}
+
+	if err := cstorage.WriteTxCntReservation(addressHash, txCnt, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+
+	return txCnt, nil
+}
+
+func ReleaseTxCnt(address [32]byte, txCnt uint32) {
+	txCntMutex.Lock()
+	defer txCntMutex.Unlock()
+
+	cstorage.DeleteTxCntReservation(protocol.SerializeHashContent(address), txCnt)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+	"time"
+)
+
+//Serializes the reads and writes of the unsigned txs with their expiry.
+var unsignedTxMutex = &sync.Mutex{}
+
+//A tx created through the REST service which waits for its signature. It is removed after util.UNSIGNED_TX_EXPIRY
+//seconds. The signer is the public key expected to sign the tx, it is empty if unknown.
+type UnsignedTx struct {
+	Hash      [32]byte
+	TypeID    uint8
+	Signer    [32]byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Tx        protocol.Transaction
+}
+
+//Store the tx until it is signed, removed or expired. The typeID is the one used to send the tx, e.g.
+//p2p.FUNDSTX_BRDCST. The signature is checked against the signer's public key once it is sent, see CheckTx.
+func AddUnsignedTx(tx protocol.Transaction, typeID uint8, signer [32]byte) (txHash [32]byte, err error) {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	pruneUnsignedTxs()
+
+	txHash = tx.Hash()
+	err = cstorage.WriteUnsignedTx(&cstorage.UnsignedTx{Hash: txHash, TypeID: typeID, CreatedAt: time.Now().Unix(), Signer: signer, Tx: tx.Encode()})
+
+	return txHash, err
+}
+
+//Replace the stored tx with the given one, e.g. once it is signed. Its expiry is not extended.
+func UpdateUnsignedTx(tx protocol.Transaction) error {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	txHash := tx.Hash()
+	stored := cstorage.ReadUnsignedTx(txHash)
+	if stored == nil {
+		return errors.New(fmt.Sprintf("No transaction with hash %x found", txHash))
+	}
+
+	stored.Tx = tx.Encode()
+
+	return cstorage.WriteUnsignedTx(stored)
+}
+
+//Returns the tx together with its signer. The tx is nil if no tx of the given type with this hash is stored or it
+//expired.
+func GetUnsignedTx(txHash [32]byte, typeID uint8) (tx protocol.Transaction, signer [32]byte) {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	stored := cstorage.ReadUnsignedTx(txHash)
+	if stored == nil || stored.TypeID != typeID {
+		return nil, signer
+	}
+
+	if unsignedTxExpired(stored) {
+		cstorage.DeleteUnsignedTx(txHash)
+		return nil, signer
+	}
+
+	return decodeUnsignedTx(stored.TypeID, stored.Tx), stored.Signer
+}
+
+//Returns the type of the stored tx. Returns false if no tx with this hash is stored or it expired.
+func GetUnsignedTxType(txHash [32]byte) (typeID uint8, ok bool) {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	stored := cstorage.ReadUnsignedTx(txHash)
+	if stored == nil || unsignedTxExpired(stored) {
+		return 0, false
+	}
+
+	return stored.TypeID, true
+}
+
+//Returns the stored txs which did not expire yet.
+func GetUnsignedTxs() (unsignedTxs []*UnsignedTx) {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	pruneUnsignedTxs()
+
+	for _, stored := range cstorage.ReadUnsignedTxs() {
+		tx := decodeUnsignedTx(stored.TypeID, stored.Tx)
+		if tx == nil {
+			continue
+		}
+
+		createdAt := time.Unix(stored.CreatedAt, 0)
+		unsignedTxs = append(unsignedTxs, &UnsignedTx{
+			Hash:      stored.Hash,
+			TypeID:    stored.TypeID,
+			Signer:    stored.Signer,
+			CreatedAt: createdAt,
+			ExpiresAt: createdAt.Add(util.UNSIGNED_TX_EXPIRY * time.Second),
+			Tx:        tx,
+		})
+	}
+
+	return unsignedTxs
+}
+
+//Returns false if no tx with this hash is stored.
+func RemoveUnsignedTx(txHash [32]byte) bool {
+	unsignedTxMutex.Lock()
+	defer unsignedTxMutex.Unlock()
+
+	if cstorage.ReadUnsignedTx(txHash) == nil {
+		return false
+	}
+
+	cstorage.DeleteUnsignedTx(txHash)
+
+	return true
+}
+
+//Called with unsignedTxMutex held.
+func pruneUnsignedTxs() {
+	for _, stored := range cstorage.ReadUnsignedTxs() {
+		if unsignedTxExpired(stored) {
+			cstorage.DeleteUnsignedTx(stored.Hash)
+		}
+	}
+}
+
+func unsignedTxExpired(stored *cstorage.UnsignedTx) bool {
+	return time.Since(time.Unix(stored.CreatedAt, 0)) > util.UNSIGNED_TX_EXPIRY*time.Second
+}
+
+func decodeUnsignedTx(typeID uint8, encoded []byte) protocol.Transaction {
+	switch typeID {
+	case p2p.ACCTX_BRDCST:
+		var accTx *protocol.AccTx
+		if accTx = accTx.Decode(encoded); accTx != nil {
+			return accTx
+		}
+	case p2p.CONFIGTX_BRDCST:
+		var configTx *protocol.ConfigTx
+		if configTx = configTx.Decode(encoded); configTx != nil {
+			return configTx
+		}
+	case p2p.FUNDSTX_BRDCST:
+		var fundsTx *protocol.FundsTx
+		if fundsTx = fundsTx.Decode(encoded); fundsTx != nil {
+			return fundsTx
+		}
+	case p2p.IOTTX_BRDCST:
+		var iotTx *protocol.IotTx
+		if iotTx = iotTx.Decode(encoded); iotTx != nil {
+			return iotTx
+		}
+	}
+
+	return nil
+}
@@ -1,31 +1,86 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
 	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 )
 
-//TODO Validate block merkle root.
+//Steps of the tx validation. A TxValidationError names the step which failed.
+const (
+	STEP_NODES_FETCH  = "fetching intermediate nodes"
+	STEP_TX_HASH      = "comparing tx hash"
+	STEP_MERKLE_PATH  = "verifying merkle path"
+	STEP_BLOCK_HEADER = "loading block header"
+	STEP_BLOCK        = "comparing block with header"
+	STEP_MERKLE_ROOT  = "comparing merkle root"
+)
 
-func validateTx(block *protocol.Block, tx protocol.Transaction, txHash [32]byte) error {
-	valid := true
+type TxValidationError struct {
+	TxHash    [32]byte
+	BlockHash [32]byte
+	Step      string
+	Err       error
+}
 
-	err := network.IntermediateNodesReq(block.Hash, txHash)
-	if err != nil {
-		//TODO
-		valid = false
+func (e *TxValidationError) Error() string {
+	return fmt.Sprintf("Tx validation failed for %x in block %x while %v: %v", e.TxHash, e.BlockHash[:8], e.Step, e.Err)
+}
+
+func (e *TxValidationError) Unwrap() error {
+	return e.Err
+}
+
+//Verify that the tx is included in the block. The block must match the block header stored in the client's database
+//and the merkle path built from the intermediate nodes must lead to the block's merkle root. Headers do not carry the
+//merkle root, but the block's hash covers it.
+func validateTx(ctx context.Context, block *protocol.Block, tx protocol.Transaction, txHash [32]byte) error {
+	newError := func(step string, err error) error {
+		return &TxValidationError{txHash, block.Hash, step, err}
+	}
+
+	blockHeader := cstorage.ReadBlockHeader(block.Hash)
+	if blockHeader == nil {
+		return newError(STEP_BLOCK_HEADER, errors.New("header not found in database"))
+	}
+
+	if err := checkBlock(block, blockHeader); err != nil {
+		return newError(STEP_BLOCK, err)
 	}
 
-	nodes, err := network.Fetch32Bytes(network.IntermediateNodesChan)
+	return validateTxInBlock(ctx, block, tx, txHash)
+}
+
+//Verify that the tx is included in the block, which must have been checked against its header by the caller.
+func validateTxInBlock(ctx context.Context, block *protocol.Block, tx protocol.Transaction, txHash [32]byte) error {
+	newError := func(step string, err error) error {
+		return &TxValidationError{txHash, block.Hash, step, err}
+	}
+
+	nodes, err := network.GetIntermediateNodes(ctx, block.Hash, txHash)
 	if err != nil {
-		//TODO
-		valid = false
+		return newError(STEP_NODES_FETCH, err)
+	}
+
+	return verifyMerklePath(block, tx, txHash, nodes)
+}
+
+//The nodes are pairs of a sibling and the resulting parent hash, from the tx up to the block's merkle root.
+func verifyMerklePath(block *protocol.Block, tx protocol.Transaction, txHash [32]byte, nodes [][32]byte) error {
+	newError := func(step string, err error) error {
+		return &TxValidationError{txHash, block.Hash, step, err}
 	}
 
 	if txHash != tx.Hash() {
-		valid = false
+		computedHash := tx.Hash()
+		return newError(STEP_TX_HASH, errors.New(fmt.Sprintf("received tx has hash %x", computedHash[:8])))
+	}
+
+	if len(nodes)%2 != 0 {
+		return newError(STEP_MERKLE_PATH, errors.New(fmt.Sprintf("odd number of intermediate nodes (%v)", len(nodes))))
 	}
 
 	leafHash := txHash
@@ -35,14 +90,14 @@ func validateTx(block *protocol.Block, tx protocol.Transaction, txHash [32]byte)
 		if parentHash = protocol.SerializeHashContent(concatHash); parentHash != nodes[i+1] {
 			concatHash = append(nodes[i][:], leafHash[:]...)
 			if parentHash = protocol.SerializeHashContent(concatHash); parentHash != nodes[i+1] {
-				valid = false
+				return newError(STEP_MERKLE_PATH, errors.New(fmt.Sprintf("node %x is not the parent of %x", nodes[i+1][:8], leafHash[:8])))
 			}
 		}
 		leafHash = parentHash
 	}
 
-	if !valid {
-		return errors.New(fmt.Sprintf("Tx validation failed for %x\n", txHash))
+	if leafHash != block.MerkleRoot {
+		return newError(STEP_MERKLE_ROOT, errors.New(fmt.Sprintf("computed root %x does not match block root %x", leafHash[:8], block.MerkleRoot[:8])))
 	}
 
 	return nil
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+//Returns a block including the tx together with the intermediate nodes of the tx.
+func testBlockWithTx(tx protocol.Transaction) (*protocol.Block, [][32]byte) {
+	txHash := tx.Hash()
+	sibling := [32]byte{0xaa}
+	root := protocol.SerializeHashContent(append(txHash[:], sibling[:]...))
+
+	block := &protocol.Block{
+		PrevHash:    [32]byte{4},
+		Height:      5,
+		Timestamp:   1554710000,
+		MerkleRoot:  root,
+		Beneficiary: [32]byte{9},
+		FundsTxData: [][32]byte{txHash, sibling},
+	}
+	block.Hash = block.HashBlock()
+
+	return block, [][32]byte{sibling, root}
+}
+
+func TestValidateTxAgainstStoredHeader(t *testing.T) {
+	cstorage.Init(filepath.Join(t.TempDir(), "client.db"))
+	defer cstorage.TearDown()
+
+	tx := &protocol.FundsTx{Amount: 10, Fee: 1, From: [32]byte{1}, To: [32]byte{2}}
+	txHash := tx.Hash()
+	block, nodes := testBlockWithTx(tx)
+
+	if err := cstorage.WriteBlockHeader(block); err != nil {
+		t.Fatal(err)
+	}
+
+	//The stored header lacks the merkle root, the tx is verified against the root of the block matching its hash.
+	header := cstorage.ReadBlockHeader(block.Hash)
+	if header == nil || header.Hash != block.Hash || header.MerkleRoot != [32]byte{} {
+		t.Fatalf("got stored header %+v", header)
+	}
+
+	if err := checkBlock(block, header); err != nil {
+		t.Fatalf("block does not match its stored header: %v", err)
+	}
+
+	if err := verifyMerklePath(block, tx, txHash, nodes); err != nil {
+		t.Errorf("merkle path not verified: %v", err)
+	}
+
+	forged := *block
+	forged.MerkleRoot = [32]byte{0xbb}
+	otherBlock, _ := testBlockWithTx(&protocol.FundsTx{Amount: 20})
+	otherSibling := [32]byte{0xdd}
+	otherRoot := protocol.SerializeHashContent(append(txHash[:], otherSibling[:]...))
+
+	tests := []struct {
+		name  string
+		block *protocol.Block
+		nodes [][32]byte
+		step  string
+	}{
+		{"forged merkle root", &forged, nil, STEP_BLOCK},
+		{"header not stored", otherBlock, nil, STEP_BLOCK_HEADER},
+		{"wrong parent", block, [][32]byte{nodes[0], {0xcc}}, STEP_MERKLE_PATH},
+		{"odd number of nodes", block, nodes[:1], STEP_MERKLE_PATH},
+		{"path to another root", block, [][32]byte{otherSibling, otherRoot}, STEP_MERKLE_ROOT},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			//Without nodes, the block is rejected before the nodes are fetched.
+			var err error
+			if test.nodes == nil {
+				err = validateTx(context.Background(), test.block, tx, txHash)
+			} else {
+				err = verifyMerklePath(test.block, tx, txHash, test.nodes)
+			}
+
+			var validationErr *TxValidationError
+			if !errors.As(err, &validationErr) || validationErr.Step != test.step {
+				t.Errorf("got %v, want step %v", err, test.step)
+			}
+		})
+	}
+}
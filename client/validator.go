@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/network"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/crypto"
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+var (
+	//The validators of the synced chain, set by InitValidators. nil if no genesis validators are configured, the
+	//beneficiaries are not verified then. The tracked stakes are only accessed with syncMutex held.
+	chainValidators *validatorSet
+)
+
+type validator struct {
+	isStaking     bool
+	stakingHeight uint32
+	commitmentKey [crypto.COMM_KEY_LENGTH]byte
+}
+
+//The stake of a beneficiary up to the header with the hash head. validator is nil if the beneficiary never staked.
+type trackedValidator struct {
+	head      [32]byte
+	validator *validator
+}
+
+//The validators of a chain, derived from the StakeTxs of its blocks. A beneficiary's StakeTxs are only searched in the
+//blocks whose bloom filter contains its address hash. The blocks are verified against the chain's headers, so the
+//commitment keys do not depend on the account state reported by a miner.
+type validatorSet struct {
+	genesis map[[32]byte]*validator
+	tracked map[[32]byte]*trackedValidator
+}
+
+//Load the genesis validators of util.Configuration. Without genesis validators, the beneficiaries of the headers are
+//not verified.
+func InitValidators() error {
+	set, err := newValidatorSet(util.Config.GenesisValidators)
+	if err != nil {
+		return err
+	}
+
+	if len(set.genesis) > 0 {
+		chainValidators = set
+	}
+
+	return nil
+}
+
+func newValidatorSet(genesisValidators []util.GenesisValidator) (*validatorSet, error) {
+	set := &validatorSet{genesis: make(map[[32]byte]*validator), tracked: make(map[[32]byte]*trackedValidator)}
+
+	for _, genesisValidator := range genesisValidators {
+		address, err := hex.DecodeString(genesisValidator.Address)
+		if err != nil || len(address) != 32 {
+			return nil, errors.New(fmt.Sprintf("genesis validator %v: invalid address", genesisValidator.Address))
+		}
+
+		commitmentKey, err := hex.DecodeString(genesisValidator.CommitmentKey)
+		if err != nil || len(commitmentKey) != crypto.COMM_KEY_LENGTH {
+			return nil, errors.New(fmt.Sprintf("genesis validator %v: invalid commitment key", genesisValidator.Address))
+		}
+
+		var addressArray [32]byte
+		copy(addressArray[:], address)
+
+		v := &validator{isStaking: true}
+		copy(v.commitmentKey[:], commitmentKey)
+		set.genesis[protocol.SerializeHashContent(addressArray)] = v
+	}
+
+	return set, nil
+}
+
+//Returns the stake of the beneficiary at the last of the given headers, from the oldest to the youngest. The StakeTxs
+//are applied on top of the tracked stake if its head is part of the headers, otherwise from the genesis block.
+func (set *validatorSet) stakeOf(ctx context.Context, headers []*protocol.Block, beneficiary [32]byte) (*validator, error) {
+	v, start := set.genesis[beneficiary], 0
+
+	if tracked := set.tracked[beneficiary]; tracked != nil {
+		for i := len(headers) - 1; i >= 0; i-- {
+			if headers[i].Hash == tracked.head {
+				v, start = tracked.validator, i+1
+				break
+			}
+		}
+	}
+
+	for _, header := range headers[start:] {
+		if !bloomFilterContains(header, beneficiary) {
+			continue
+		}
+
+		var err error
+		if v, err = applyStakeTxs(ctx, header, beneficiary, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(headers) > 0 {
+		set.tracked[beneficiary] = &trackedValidator{headers[len(headers)-1].Hash, v}
+	}
+
+	return v, nil
+}
+
+//Apply the beneficiary's StakeTxs of the header's block to its stake v.
+func applyStakeTxs(ctx context.Context, header *protocol.Block, beneficiary [32]byte, v *validator) (*validator, error) {
+	blocks, err := getRelevantBlocks(ctx, []*protocol.Block{header})
+	if err != nil {
+		return nil, err
+	}
+
+	block := blocks[0]
+	if block == nil {
+		return nil, errors.New(fmt.Sprintf("block %x is not available", header.Hash[:8]))
+	}
+
+	for _, txHash := range block.StakeTxData {
+		tx, err := network.GetTx(ctx, p2p.STAKETX_REQ, txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		stakeTx, ok := tx.(*protocol.StakeTx)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("tx %x of block %x is no stake tx", txHash[:8], header.Hash[:8]))
+		}
+
+		if stakeTx.Account != beneficiary {
+			continue
+		}
+
+		if err := validateTxInBlock(ctx, block, tx, txHash); err != nil {
+			return nil, err
+		}
+
+		v = &validator{stakeTx.IsStaking, header.Height, stakeTx.CommitmentKey}
+	}
+
+	return v, nil
+}
+
+//The beneficiary must be staking, its stake must have waited for the minimum number of blocks and the commitment proof
+//must be signed with its commitment key. The minimum stake is checked by the miners when they include the StakeTx.
+func verifyBeneficiary(header *protocol.Block, v *validator, parameters miner.Parameters) (check string, err error) {
+	if v == nil || !v.isStaking {
+		return CHECK_VALIDATOR, errors.New(fmt.Sprintf("beneficiary %x is not staking", header.Beneficiary[:8]))
+	}
+
+	if v.stakingHeight > 0 && uint64(header.Height-v.stakingHeight) < parameters.Waiting_minimum {
+		return CHECK_STAKE, errors.New(fmt.Sprintf("beneficiary %x started staking at height %v, it must wait %v blocks",
+			header.Beneficiary[:8], v.stakingHeight, parameters.Waiting_minimum))
+	}
+
+	commitmentKey, err := crypto.CreateRSAPubKeyFromBytes(v.commitmentKey)
+	if err != nil {
+		return CHECK_COMMITMENT, err
+	}
+
+	//The beneficiary proves its right to create the block by signing the block height with its commitment key.
+	if err := crypto.VerifyMessageWithRSA(commitmentKey, fmt.Sprint(header.Height), header.CommitmentProof); err != nil {
+		return CHECK_COMMITMENT, err
+	}
+
+	return "", nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/miner"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestVerifyBeneficiary(t *testing.T) {
+	parameters := miner.Parameters{Waiting_minimum: 10}
+
+	tests := []struct {
+		name      string
+		validator *validator
+		height    uint32
+		check     string
+	}{
+		{"staking validator", &validator{isStaking: true, stakingHeight: 10}, 100, ""},
+		{"genesis validator", &validator{isStaking: true}, 1, ""},
+		{"never staked", nil, 100, CHECK_VALIDATOR},
+		{"stake withdrawn", &validator{isStaking: false, stakingHeight: 10}, 100, CHECK_VALIDATOR},
+		{"waiting minimum not reached", &validator{isStaking: true, stakingHeight: 95}, 104, CHECK_STAKE},
+		{"waiting minimum reached", &validator{isStaking: true, stakingHeight: 95}, 105, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			check, err := verifyBeneficiary(&protocol.Block{Beneficiary: [32]byte{1}, Height: test.height}, test.validator, parameters)
+			if check != test.check {
+				t.Errorf("got check %q (%v), want %q", check, err, test.check)
+			}
+
+			if (err != nil) != (test.check != "") {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
+
+func TestNewValidatorSet(t *testing.T) {
+	address := strings.Repeat("ab", 32)
+	commitmentKey := strings.Repeat("cd", 512)
+
+	tests := []struct {
+		name       string
+		validators []util.GenesisValidator
+		genesis    int
+		wantErr    bool
+	}{
+		{"none", nil, 0, false},
+		{"valid", []util.GenesisValidator{{Address: address, CommitmentKey: commitmentKey}}, 1, false},
+		{"short address", []util.GenesisValidator{{Address: "ab", CommitmentKey: commitmentKey}}, 0, true},
+		{"short commitment key", []util.GenesisValidator{{Address: address, CommitmentKey: "cd"}}, 0, true},
+		{"commitment key not hex", []util.GenesisValidator{{Address: address, CommitmentKey: strings.Repeat("zz", 512)}}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set, err := newValidatorSet(test.validators)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+
+			if err == nil && len(set.genesis) != test.genesis {
+				t.Errorf("got %v genesis validators, want %v", len(set.genesis), test.genesis)
+			}
+		})
+	}
+
+	set, _ := newValidatorSet([]util.GenesisValidator{{Address: address, CommitmentKey: commitmentKey}})
+
+	var addressArray [32]byte
+	decoded, _ := hex.DecodeString(address)
+	copy(addressArray[:], decoded)
+
+	v := set.genesis[protocol.SerializeHashContent(addressArray)]
+	if v == nil || !v.isStaking || v.stakingHeight != 0 {
+		t.Fatalf("got validator %+v", v)
+	}
+
+	if hex.EncodeToString(v.commitmentKey[:]) != commitmentKey {
+		t.Errorf("commitment key does not match")
+	}
+}
+
+func TestStakeOf(t *testing.T) {
+	genesis, tracked := [32]byte{1}, [32]byte{2}
+	genesisValidator := &validator{isStaking: true}
+	trackedStake := &validator{isStaking: true, stakingHeight: 1}
+
+	//No header's bloom filter contains the beneficiaries, so no block is fetched.
+	var headers []*protocol.Block
+	for height := uint32(0); height < 4; height++ {
+		headers = append(headers, &protocol.Block{Height: height, Hash: [32]byte{byte(height)}})
+	}
+
+	tests := []struct {
+		name        string
+		beneficiary [32]byte
+		trackedHead [32]byte
+		want        *validator
+	}{
+		{"genesis validator", genesis, [32]byte{}, genesisValidator},
+		{"unknown beneficiary", [32]byte{3}, [32]byte{}, nil},
+		{"tracked stake", tracked, headers[1].Hash, trackedStake},
+		{"tracked stake of an orphaned header", tracked, [32]byte{0xff}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := &validatorSet{
+				genesis: map[[32]byte]*validator{genesis: genesisValidator},
+				tracked: map[[32]byte]*trackedValidator{tracked: {test.trackedHead, trackedStake}},
+			}
+
+			v, err := set.stakeOf(context.Background(), headers, test.beneficiary)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != test.want {
+				t.Errorf("got validator %+v, want %+v", v, test.want)
+			}
+
+			//The stake is tracked up to the last header.
+			if tracked := set.tracked[test.beneficiary]; tracked == nil || tracked.head != headers[3].Hash || tracked.validator != v {
+				t.Errorf("got tracked stake %+v", tracked)
+			}
+		})
+	}
+}
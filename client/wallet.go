@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/cstorage"
+	"regexp"
+	"sync"
+)
+
+//Names are used in place of wallet files and addresses, so they can be told apart from both: they contain neither dots
+//nor slashes and are too short to be an address.
+var walletNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+//Serializes the checks for taken names with the writes.
+var walletMutex = &sync.Mutex{}
+
+//Accounts and contacts share their names, so a name identifies exactly one address.
+func AddWalletAccount(name string, address [32]byte, file string) error {
+	walletMutex.Lock()
+	defer walletMutex.Unlock()
+
+	if err := checkWalletName(name); err != nil {
+		return err
+	}
+
+	return cstorage.WriteWalletAccount(&cstorage.WalletAccount{Name: name, Address: address, File: file})
+}
+
+func AddContact(name string, address [32]byte) error {
+	walletMutex.Lock()
+	defer walletMutex.Unlock()
+
+	if err := checkWalletName(name); err != nil {
+		return err
+	}
+
+	return cstorage.WriteContact(&cstorage.Contact{Name: name, Address: address})
+}
+
+func RemoveWalletAccount(name string) error {
+	walletMutex.Lock()
+	defer walletMutex.Unlock()
+
+	if cstorage.ReadWalletAccount(name) == nil {
+		return errors.New(fmt.Sprintf("Wallet account %v does not exist.", name))
+	}
+
+	cstorage.DeleteWalletAccount(name)
+
+	return nil
+}
+
+func RemoveContact(name string) error {
+	walletMutex.Lock()
+	defer walletMutex.Unlock()
+
+	if cstorage.ReadContact(name) == nil {
+		return errors.New(fmt.Sprintf("Contact %v does not exist.", name))
+	}
+
+	cstorage.DeleteContact(name)
+
+	return nil
+}
+
+//Returns nil if no wallet account has the given name.
+func GetWalletAccount(name string) *cstorage.WalletAccount {
+	if !walletNamePattern.MatchString(name) {
+		return nil
+	}
+
+	return cstorage.ReadWalletAccount(name)
+}
+
+func GetWalletAccounts() []*cstorage.WalletAccount {
+	return cstorage.ReadWalletAccounts()
+}
+
+//Returns nil if no contact has the given name.
+func GetContact(name string) *cstorage.Contact {
+	if !walletNamePattern.MatchString(name) {
+		return nil
+	}
+
+	return cstorage.ReadContact(name)
+}
+
+func GetContacts() []*cstorage.Contact {
+	return cstorage.ReadContacts()
+}
+
+//Returns the address of the wallet account or contact with the given name.
+func LookupAddress(name string) (address [32]byte, ok bool) {
+	if account := GetWalletAccount(name); account != nil {
+		return account.Address, true
+	}
+
+	if contact := GetContact(name); contact != nil {
+		return contact.Address, true
+	}
+
+	return address, false
+}
+
+func checkWalletName(name string) error {
+	if !walletNamePattern.MatchString(name) {
+		return errors.New(fmt.Sprintf("Invalid name %v, names consist of up to 32 letters, digits, - and _.", name))
+	}
+
+	if cstorage.ReadWalletAccount(name) != nil || cstorage.ReadContact(name) != nil {
+		return errors.New(fmt.Sprintf("The name %v is taken already.", name))
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package cstorage
 
-import "github.com/boltdb/bolt"
+import (
+	"encoding/binary"
+	"github.com/boltdb/bolt"
+)
 
 func DeleteBlockHeader(hash [32]byte) {
 	db.Update(func(tx *bolt.Tx) error {
@@ -10,3 +13,129 @@ func DeleteBlockHeader(hash [32]byte) {
 		return err
 	})
 }
+
+//Remove all indexed transactions above the given height and lower the index heights accordingly. Used when headers
+//above this height are rolled back. Returns the hashes of the removed transactions.
+func DeleteIndexAbove(height uint32) (txHashes [][32]byte) {
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("txindex"))
+		var staleKeys [][]byte
+		removed := make(map[[32]byte]bool)
+		b.ForEach(func(k, v []byte) error {
+			if len(k) == 32+4+32 && binary.BigEndian.Uint32(k[32:36]) > height {
+				staleKeys = append(staleKeys, append([]byte{}, k...))
+
+				var txHash [32]byte
+				copy(txHash[:], k[36:])
+				if !removed[txHash] {
+					removed[txHash] = true
+					txHashes = append(txHashes, txHash)
+				}
+			}
+
+			return nil
+		})
+
+		for _, k := range staleKeys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		var encodedHeight [4]byte
+		binary.BigEndian.PutUint32(encodedHeight[:], height)
+
+		hb := tx.Bucket([]byte("indexheights"))
+		var staleHeights [][]byte
+		hb.ForEach(func(k, v []byte) error {
+			if len(v) == 4 && binary.BigEndian.Uint32(v) > height {
+				staleHeights = append(staleHeights, append([]byte{}, k...))
+			}
+
+			return nil
+		})
+
+		for _, k := range staleHeights {
+			if err := hb.Put(k, encodedHeight[:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return txHashes
+}
+
+//Remove all indexed transactions and index heights and mark the empty index with the current INDEX_VERSION.
+func DeleteIndex() (err error) {
+	var encodedVersion [4]byte
+	binary.BigEndian.PutUint32(encodedVersion[:], INDEX_VERSION)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{"txindex", "indexheights"} {
+			if err := tx.DeleteBucket([]byte(bucket)); err != nil {
+				return err
+			}
+
+			if _, err := tx.CreateBucket([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+
+		b := tx.Bucket([]byte("indexversion"))
+		err := b.Put(indexVersionKey, encodedVersion[:])
+
+		return err
+	})
+
+	return err
+}
+
+func DeleteTxCntReservation(addressHash [32]byte, txCnt uint32) {
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("txcnts"))
+		err := b.Delete(txCntKey(addressHash, txCnt))
+
+		return err
+	})
+}
+
+func DeleteSyncHeaders() {
+	db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte("syncheaders")); err != nil {
+			return err
+		}
+
+		_, err := tx.CreateBucket([]byte("syncheaders"))
+
+		return err
+	})
+}
+
+func DeleteWalletAccount(name string) {
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("walletaccounts"))
+		err := b.Delete([]byte(name))
+
+		return err
+	})
+}
+
+func DeleteContact(name string) {
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("contacts"))
+		err := b.Delete([]byte(name))
+
+		return err
+	})
+}
+
+func DeleteUnsignedTx(hash [32]byte) {
+	db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("unsignedtxs"))
+		err := b.Delete(hash[:])
+
+		return err
+	})
+}
@@ -1,6 +1,8 @@
 package cstorage
 
 import (
+	"bytes"
+	"encoding/binary"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/boltdb/bolt"
 )
@@ -37,3 +39,207 @@ func ReadLastBlockHeader() (header *protocol.Block) {
 
 	return header
 }
+
+//Returns all indexed transactions of the given address hash, ordered by block height.
+func ReadIndexedTxs(addressHash [32]byte) (indexedTxs []*IndexedTx) {
+	db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte("txindex")).Cursor()
+		for k, v := c.Seek(addressHash[:]); k != nil && bytes.HasPrefix(k, addressHash[:]); k, v = c.Next() {
+			if indexedTx := decodeIndexedTx(k, v); indexedTx != nil {
+				indexedTxs = append(indexedTxs, indexedTx)
+			}
+		}
+
+		return nil
+	})
+
+	return indexedTxs
+}
+
+//Returns the index height of the given address hash. ok is false if the address has never been indexed.
+func ReadIndexHeight(addressHash [32]byte) (height uint32, ok bool) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("indexheights"))
+		if encodedHeight := b.Get(addressHash[:]); len(encodedHeight) == 4 {
+			height = binary.BigEndian.Uint32(encodedHeight)
+			ok = true
+		}
+
+		return nil
+	})
+
+	return height, ok
+}
+
+//Returns 0 if no index has been written yet or it predates the versioning.
+func ReadIndexVersion() (version uint32) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("indexversion"))
+		if encodedVersion := b.Get(indexVersionKey); len(encodedVersion) == 4 {
+			version = binary.BigEndian.Uint32(encodedVersion)
+		}
+
+		return nil
+	})
+
+	return version
+}
+
+//Returns all address hashes for which an index exists.
+func ReadIndexedAddresses() (addressHashes [][32]byte) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("indexheights"))
+		b.ForEach(func(k, v []byte) error {
+			var addressHash [32]byte
+			copy(addressHash[:], k)
+			addressHashes = append(addressHashes, addressHash)
+
+			return nil
+		})
+
+		return nil
+	})
+
+	return addressHashes
+}
+
+//Returns the reserved TxCnts of the given address hash together with the unix time of their reservation.
+func ReadTxCntReservations(addressHash [32]byte) (reservations map[uint32]int64) {
+	reservations = make(map[uint32]int64)
+
+	db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte("txcnts")).Cursor()
+		for k, v := c.Seek(addressHash[:]); k != nil && bytes.HasPrefix(k, addressHash[:]); k, v = c.Next() {
+			if len(k) == 32+4 && len(v) == 8 {
+				reservations[binary.BigEndian.Uint32(k[32:])] = int64(binary.BigEndian.Uint64(v))
+			}
+		}
+
+		return nil
+	})
+
+	return reservations
+}
+
+func ReadSyncHeader(hash [32]byte) (header *protocol.Block) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("syncheaders"))
+		encodedHeader := b.Get(hash[:])
+		header = header.Decode(encodedHeader)
+
+		return nil
+	})
+
+	if header == nil {
+		return nil
+	}
+
+	return header
+}
+
+func ReadWalletAccount(name string) (account *WalletAccount) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("walletaccounts"))
+		if encoded := b.Get([]byte(name)); encoded != nil {
+			account = decodeWalletAccount([]byte(name), encoded)
+		}
+
+		return nil
+	})
+
+	return account
+}
+
+//Returns the wallet accounts ordered by name.
+func ReadWalletAccounts() (accounts []*WalletAccount) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("walletaccounts"))
+		b.ForEach(func(k, v []byte) error {
+			if account := decodeWalletAccount(k, v); account != nil {
+				accounts = append(accounts, account)
+			}
+
+			return nil
+		})
+
+		return nil
+	})
+
+	return accounts
+}
+
+func ReadContact(name string) (contact *Contact) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("contacts"))
+		if encoded := b.Get([]byte(name)); encoded != nil {
+			contact = decodeContact([]byte(name), encoded)
+		}
+
+		return nil
+	})
+
+	return contact
+}
+
+//Returns the contacts ordered by name.
+func ReadContacts() (contacts []*Contact) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("contacts"))
+		b.ForEach(func(k, v []byte) error {
+			if contact := decodeContact(k, v); contact != nil {
+				contacts = append(contacts, contact)
+			}
+
+			return nil
+		})
+
+		return nil
+	})
+
+	return contacts
+}
+
+//Returns nil if the HD wallet is not initialized.
+func ReadHDSeed() (encryptedSeed []byte) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("hdwallet"))
+		if encoded := b.Get(hdSeedKey); encoded != nil {
+			//The value is only valid during the transaction.
+			encryptedSeed = append([]byte{}, encoded...)
+		}
+
+		return nil
+	})
+
+	return encryptedSeed
+}
+
+func ReadUnsignedTx(hash [32]byte) (unsignedTx *UnsignedTx) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("unsignedtxs"))
+		if encoded := b.Get(hash[:]); encoded != nil {
+			unsignedTx = decodeUnsignedTx(hash[:], encoded)
+		}
+
+		return nil
+	})
+
+	return unsignedTx
+}
+
+func ReadUnsignedTxs() (unsignedTxs []*UnsignedTx) {
+	db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("unsignedtxs"))
+		b.ForEach(func(k, v []byte) error {
+			if unsignedTx := decodeUnsignedTx(k, v); unsignedTx != nil {
+				unsignedTxs = append(unsignedTxs, unsignedTx)
+			}
+
+			return nil
+		})
+
+		return nil
+	})
+
+	return unsignedTxs
+}
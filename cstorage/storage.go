@@ -44,6 +44,97 @@ func Init(dbname string) {
 
 		return nil
 	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("txindex"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("indexheights"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("syncheaders"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("txcnts"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("walletaccounts"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("contacts"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("hdwallet"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("unsignedtxs"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	db.Update(func(tx *bolt.Tx) error {
+		_, err = tx.CreateBucket([]byte("indexversion"))
+		if err != nil {
+			return fmt.Errorf(ERROR_MSG+"Create bucket: %s", err)
+		}
+
+		return nil
+	})
+
+	if version := ReadIndexVersion(); version != INDEX_VERSION {
+		if version != 0 {
+			logger.Printf("Index version %v is outdated, the index is rebuilt\n", version)
+		}
+
+		if err := DeleteIndex(); err != nil {
+			logger.Fatal(ERROR_MSG, err)
+		}
+	}
 }
 
 func TearDown() {
@@ -0,0 +1,54 @@
+package cstorage
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+func TestInitRebuildsOutdatedIndex(t *testing.T) {
+	dbname := filepath.Join(t.TempDir(), "client.db")
+	addressHash := [32]byte{1}
+
+	tests := []struct {
+		name    string
+		version uint32
+		kept    bool
+	}{
+		{"current version", INDEX_VERSION, true},
+		{"outdated version", INDEX_VERSION - 1, false},
+		{"unversioned", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Init(dbname)
+			WriteIndexHeight(addressHash, 10)
+
+			var encodedVersion [4]byte
+			binary.BigEndian.PutUint32(encodedVersion[:], test.version)
+			db.Update(func(tx *bolt.Tx) error {
+				b := tx.Bucket([]byte("indexversion"))
+				if test.version == 0 {
+					return b.Delete(indexVersionKey)
+				}
+
+				return b.Put(indexVersionKey, encodedVersion[:])
+			})
+			TearDown()
+
+			Init(dbname)
+			defer TearDown()
+
+			if _, ok := ReadIndexHeight(addressHash); ok != test.kept {
+				t.Errorf("index kept: got %v, want %v", ok, test.kept)
+			}
+
+			if version := ReadIndexVersion(); version != INDEX_VERSION {
+				t.Errorf("version: got %v, want %v", version, INDEX_VERSION)
+			}
+		})
+	}
+}
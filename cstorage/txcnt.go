@@ -0,0 +1,12 @@
+package cstorage
+
+import "encoding/binary"
+
+//Keys of TxCnt reservations are built as addressHash|txCnt.
+func txCntKey(addressHash [32]byte, txCnt uint32) []byte {
+	key := make([]byte, 32+4)
+	copy(key[:32], addressHash[:])
+	binary.BigEndian.PutUint32(key[32:], txCnt)
+
+	return key
+}
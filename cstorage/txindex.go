@@ -0,0 +1,100 @@
+package cstorage
+
+import (
+	"encoding/binary"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+const (
+	FUNDSTX  = 1
+	ACCTX    = 2
+	CONFIGTX = 3
+	STAKETX  = 4
+)
+
+//Bumped whenever indexBlock indexes other transactions or under other address hashes. An index of an older version is
+//removed at startup and rebuilt with the next state queries.
+const INDEX_VERSION = 4
+
+var indexVersionKey = []byte("version")
+
+//A verified transaction that is relevant for an account, together with the block it was included in.
+type IndexedTx struct {
+	BlockHash [32]byte
+	Height    uint32
+	TxHash    [32]byte
+	Tx        protocol.Transaction
+}
+
+//Keys are built as addressHash|height|txHash, so a cursor over an address prefix iterates its transactions ordered by height.
+func indexKey(addressHash [32]byte, height uint32, txHash [32]byte) []byte {
+	key := make([]byte, 32+4+32)
+	copy(key[:32], addressHash[:])
+	binary.BigEndian.PutUint32(key[32:36], height)
+	copy(key[36:], txHash[:])
+
+	return key
+}
+
+func encodeIndexedTx(indexedTx *IndexedTx) []byte {
+	var txType byte
+	switch indexedTx.Tx.(type) {
+	case *protocol.FundsTx:
+		txType = FUNDSTX
+	case *protocol.AccTx:
+		txType = ACCTX
+	case *protocol.ConfigTx:
+		txType = CONFIGTX
+	case *protocol.StakeTx:
+		txType = STAKETX
+	}
+
+	encoded := make([]byte, 32+1)
+	copy(encoded[:32], indexedTx.BlockHash[:])
+	encoded[32] = txType
+
+	return append(encoded, indexedTx.Tx.Encode()...)
+}
+
+func decodeIndexedTx(key []byte, encoded []byte) *IndexedTx {
+	if len(key) != 32+4+32 || len(encoded) < 32+1 {
+		return nil
+	}
+
+	indexedTx := new(IndexedTx)
+	copy(indexedTx.BlockHash[:], encoded[:32])
+	indexedTx.Height = binary.BigEndian.Uint32(key[32:36])
+	copy(indexedTx.TxHash[:], key[36:])
+
+	data := encoded[33:]
+	switch encoded[32] {
+	case FUNDSTX:
+		var fundsTx *protocol.FundsTx
+		if fundsTx = fundsTx.Decode(data); fundsTx == nil {
+			return nil
+		}
+		indexedTx.Tx = fundsTx
+	case ACCTX:
+		var accTx *protocol.AccTx
+		if accTx = accTx.Decode(data); accTx == nil {
+			return nil
+		}
+		indexedTx.Tx = accTx
+	case CONFIGTX:
+		var configTx *protocol.ConfigTx
+		if configTx = configTx.Decode(data); configTx == nil {
+			return nil
+		}
+		indexedTx.Tx = configTx
+	case STAKETX:
+		var stakeTx *protocol.StakeTx
+		if stakeTx = stakeTx.Decode(data); stakeTx == nil {
+			return nil
+		}
+		indexedTx.Tx = stakeTx
+	default:
+		return nil
+	}
+
+	return indexedTx
+}
@@ -0,0 +1,75 @@
+package cstorage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestIndexedTxEncoding(t *testing.T) {
+	blockHash := [32]byte{1}
+	txHash := [32]byte{2}
+
+	tests := []struct {
+		name string
+		tx   protocol.Transaction
+	}{
+		{"FundsTx", &protocol.FundsTx{Amount: 10, Fee: 1, TxCnt: 3, From: [32]byte{3}, To: [32]byte{4}}},
+		{"AccTx", &protocol.AccTx{Issuer: [32]byte{5}, Fee: 1, PubKey: [32]byte{6}}},
+		{"ConfigTx", &protocol.ConfigTx{Id: 3, Payload: 100, Fee: 1}},
+		{"StakeTx", &protocol.StakeTx{Fee: 1, IsStaking: true, Account: [32]byte{7}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexedTx := &IndexedTx{BlockHash: blockHash, Height: 42, TxHash: txHash, Tx: test.tx}
+
+			decoded := decodeIndexedTx(indexKey([32]byte{9}, 42, txHash), encodeIndexedTx(indexedTx))
+			if decoded == nil {
+				t.Fatal("decodeIndexedTx returned nil")
+			}
+
+			if !reflect.DeepEqual(decoded, indexedTx) {
+				t.Errorf("got %+v, want %+v", decoded, indexedTx)
+			}
+		})
+	}
+}
+
+func TestDecodeIndexedTxInvalid(t *testing.T) {
+	key := indexKey([32]byte{9}, 1, [32]byte{2})
+	valid := encodeIndexedTx(&IndexedTx{Tx: &protocol.FundsTx{Amount: 1}})
+
+	unknownType := append([]byte{}, valid...)
+	unknownType[32] = 0
+
+	tests := []struct {
+		name    string
+		key     []byte
+		encoded []byte
+	}{
+		{"short key", key[:36], valid},
+		{"short value", key, valid[:32]},
+		{"unknown type", key, unknownType},
+		{"missing tx", key, valid[:33]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if decoded := decodeIndexedTx(test.key, test.encoded); decoded != nil {
+				t.Errorf("got %+v, want nil", decoded)
+			}
+		})
+	}
+}
+
+func TestIndexKeyOrder(t *testing.T) {
+	addressHash := [32]byte{9}
+
+	low := indexKey(addressHash, 255, [32]byte{0xff})
+	high := indexKey(addressHash, 256, [32]byte{})
+	if string(low) >= string(high) {
+		t.Error("keys are not ordered by height")
+	}
+}
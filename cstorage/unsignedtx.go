@@ -0,0 +1,40 @@
+package cstorage
+
+import "encoding/binary"
+
+//A tx created through the REST service, which waits for the signature of its sender. The signer is the public key
+//expected to sign the tx, it is empty if unknown.
+type UnsignedTx struct {
+	Hash      [32]byte
+	TypeID    uint8
+	CreatedAt int64
+	Signer    [32]byte
+	Tx        []byte
+}
+
+//Unsigned txs are stored as typeID|createdAt|signer|tx under their hash.
+func encodeUnsignedTx(unsignedTx *UnsignedTx) []byte {
+	encoded := make([]byte, 1+8+32, 1+8+32+len(unsignedTx.Tx))
+	encoded[0] = unsignedTx.TypeID
+	binary.BigEndian.PutUint64(encoded[1:9], uint64(unsignedTx.CreatedAt))
+	copy(encoded[9:41], unsignedTx.Signer[:])
+
+	return append(encoded, unsignedTx.Tx...)
+}
+
+func decodeUnsignedTx(hash []byte, encoded []byte) *UnsignedTx {
+	if len(hash) != 32 || len(encoded) < 1+8+32 {
+		return nil
+	}
+
+	unsignedTx := &UnsignedTx{
+		TypeID:    encoded[0],
+		CreatedAt: int64(binary.BigEndian.Uint64(encoded[1:9])),
+		//The value is only valid during the transaction.
+		Tx: append([]byte{}, encoded[41:]...),
+	}
+	copy(unsignedTx.Hash[:], hash)
+	copy(unsignedTx.Signer[:], encoded[9:41])
+
+	return unsignedTx
+}
@@ -0,0 +1,97 @@
+package cstorage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestUnsignedTxEncoding(t *testing.T) {
+	fundsTx := &protocol.FundsTx{Amount: 10, Fee: 1, TxCnt: 3, From: [32]byte{3}, To: [32]byte{4}, Data: []byte("data")}
+
+	tests := []struct {
+		name       string
+		unsignedTx *UnsignedTx
+	}{
+		{"FundsTx", &UnsignedTx{Hash: [32]byte{1}, TypeID: 1, CreatedAt: 1600000000, Signer: [32]byte{3}, Tx: fundsTx.Encode()}},
+		{"unknown signer", &UnsignedTx{Hash: [32]byte{2}, TypeID: 2, CreatedAt: 1600000000, Tx: []byte{1, 2, 3}}},
+		{"empty tx", &UnsignedTx{Hash: [32]byte{3}, TypeID: 3, CreatedAt: 1, Tx: []byte{}}},
+		{"negative timestamp", &UnsignedTx{Hash: [32]byte{4}, CreatedAt: -1, Tx: []byte{0}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded := encodeUnsignedTx(test.unsignedTx)
+
+			decoded := decodeUnsignedTx(test.unsignedTx.Hash[:], encoded)
+			if decoded == nil {
+				t.Fatal("decodeUnsignedTx returned nil")
+			}
+
+			if !reflect.DeepEqual(decoded, test.unsignedTx) {
+				t.Errorf("got %+v, want %+v", decoded, test.unsignedTx)
+			}
+
+			//The decoded tx must not share memory with the encoded value, which bolt reuses after the transaction.
+			for i := range encoded {
+				encoded[i] = 0xff
+			}
+
+			if !reflect.DeepEqual(decoded, test.unsignedTx) {
+				t.Errorf("decoded tx changed with the encoded value")
+			}
+		})
+	}
+}
+
+func TestDecodeUnsignedTxInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    []byte
+		encoded []byte
+	}{
+		{"short hash", make([]byte, 31), make([]byte, 41)},
+		{"long hash", make([]byte, 33), make([]byte, 41)},
+		{"empty value", make([]byte, 32), nil},
+		{"truncated signer", make([]byte, 32), make([]byte, 40)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if decoded := decodeUnsignedTx(test.hash, test.encoded); decoded != nil {
+				t.Errorf("got %+v, want nil", decoded)
+			}
+		})
+	}
+}
+
+func TestUnsignedTxStorage(t *testing.T) {
+	Init(filepath.Join(t.TempDir(), "client.db"))
+	defer TearDown()
+
+	unsignedTxs := []*UnsignedTx{
+		{Hash: [32]byte{1}, TypeID: 1, CreatedAt: 10, Signer: [32]byte{5}, Tx: []byte{1}},
+		{Hash: [32]byte{2}, TypeID: 2, CreatedAt: 20, Tx: []byte{2, 2}},
+	}
+
+	for _, unsignedTx := range unsignedTxs {
+		if err := WriteUnsignedTx(unsignedTx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if read := ReadUnsignedTx([32]byte{1}); !reflect.DeepEqual(read, unsignedTxs[0]) {
+		t.Errorf("got %+v, want %+v", read, unsignedTxs[0])
+	}
+
+	if read := ReadUnsignedTxs(); !reflect.DeepEqual(read, unsignedTxs) {
+		t.Errorf("got %+v, want %+v", read, unsignedTxs)
+	}
+
+	DeleteUnsignedTx([32]byte{1})
+	if read := ReadUnsignedTx([32]byte{1}); read != nil {
+		t.Errorf("got %+v after deleting it", read)
+	}
+}
@@ -0,0 +1,44 @@
+package cstorage
+
+//The key of the encrypted seed in the hdwallet bucket.
+var hdSeedKey = []byte("seed")
+
+//A named account of the local wallet. Its private key stays in the wallet file, only the file's path is stored.
+type WalletAccount struct {
+	Name    string
+	Address [32]byte
+	File    string
+}
+
+//A named address of the address book.
+type Contact struct {
+	Name    string
+	Address [32]byte
+}
+
+//Wallet accounts are stored as address|file under their name.
+func encodeWalletAccount(account *WalletAccount) []byte {
+	return append(account.Address[:], []byte(account.File)...)
+}
+
+func decodeWalletAccount(name []byte, encoded []byte) *WalletAccount {
+	if len(encoded) < 32 {
+		return nil
+	}
+
+	account := &WalletAccount{Name: string(name), File: string(encoded[32:])}
+	copy(account.Address[:], encoded[:32])
+
+	return account
+}
+
+func decodeContact(name []byte, encoded []byte) *Contact {
+	if len(encoded) != 32 {
+		return nil
+	}
+
+	contact := &Contact{Name: string(name)}
+	copy(contact.Address[:], encoded)
+
+	return contact
+}
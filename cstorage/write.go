@@ -1,6 +1,7 @@
 package cstorage
 
 import (
+	"encoding/binary"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 	"github.com/boltdb/bolt"
 )
@@ -38,3 +39,104 @@ func WriteLastBlockHeader(header *protocol.Block) (err error) {
 
 	return err
 }
+
+//Add a verified transaction to the index of the given address hash.
+func WriteIndexedTx(addressHash [32]byte, indexedTx *IndexedTx) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("txindex"))
+		err := b.Put(indexKey(addressHash, indexedTx.Height, indexedTx.TxHash), encodeIndexedTx(indexedTx))
+
+		return err
+	})
+
+	return err
+}
+
+//The index height is the height of the youngest block whose transactions are indexed for the given address hash.
+func WriteIndexHeight(addressHash [32]byte, height uint32) (err error) {
+	var encodedHeight [4]byte
+	binary.BigEndian.PutUint32(encodedHeight[:], height)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("indexheights"))
+		err := b.Put(addressHash[:], encodedHeight[:])
+
+		return err
+	})
+
+	return err
+}
+
+//A TxCnt handed out for a tx of the given address hash, which is not yet part of the account's state. The value is the
+//unix time of the reservation.
+func WriteTxCntReservation(addressHash [32]byte, txCnt uint32, reservedAt int64) (err error) {
+	var encodedTime [8]byte
+	binary.BigEndian.PutUint64(encodedTime[:], uint64(reservedAt))
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("txcnts"))
+		err := b.Put(txCntKey(addressHash, txCnt), encodedTime[:])
+
+		return err
+	})
+
+	return err
+}
+
+//Headers fetched by a running sync. They are kept until the sync completes, so an interrupted sync can resume.
+func WriteSyncHeader(header *protocol.Block) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("syncheaders"))
+		err := b.Put(header.Hash[:], header.EncodeHeader())
+
+		return err
+	})
+
+	return err
+}
+
+func WriteWalletAccount(account *WalletAccount) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("walletaccounts"))
+		err := b.Put([]byte(account.Name), encodeWalletAccount(account))
+
+		return err
+	})
+
+	return err
+}
+
+func WriteContact(contact *Contact) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("contacts"))
+		err := b.Put([]byte(contact.Name), contact.Address[:])
+
+		return err
+	})
+
+	return err
+}
+
+//The seed of the HD wallet, encrypted by the client. An existing seed is replaced.
+func WriteHDSeed(encryptedSeed []byte) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("hdwallet"))
+		err := b.Put(hdSeedKey, encryptedSeed)
+
+		return err
+	})
+
+	return err
+}
+
+//An existing tx with the same hash is replaced, e.g. once it is signed.
+func WriteUnsignedTx(unsignedTx *UnsignedTx) (err error) {
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("unsignedtxs"))
+		err := b.Put(unsignedTx.Hash[:], encodeUnsignedTx(unsignedTx))
+
+		return err
+	})
+
+	return err
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/bazo-blockchain/bazo-client/cli"
 	"github.com/bazo-blockchain/bazo-client/client"
 	"github.com/bazo-blockchain/bazo-client/cstorage"
-	"github.com/bazo-blockchain/bazo-client/network"
 	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	cli2 "github.com/urfave/cli"
@@ -17,7 +16,10 @@ func main() {
 	logger := util.InitLogger()
 	util.Config = util.LoadConfiguration()
 
-	network.Init()
+	if err := client.InitValidators(); err != nil {
+		logger.Fatal(err)
+	}
+
 	cstorage.Init("client.db")
 
 	app := cli2.NewApp()
@@ -27,10 +29,15 @@ func main() {
 	app.Version = "1.0.0"
 	app.Commands = []cli2.Command {
 		cli.GetAccountCommand(logger),
+		cli.GetFeeCommand(logger),
 		cli.GetFundsCommand(logger),
+		cli.GetKeystoreCommand(logger),
 		cli.GetNetworkCommand(logger),
 		cli.GetRestCommand(),
 		cli.GetStakingCommand(logger),
+		cli.GetSyncCommand(logger),
+		cli.GetTxCommand(logger),
+		cli.GetWalletCommand(logger),
 	}
 
 	err := app.Run(os.Args)
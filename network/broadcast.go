@@ -0,0 +1,74 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"sync"
+)
+
+//The outcome of sending a tx to one miner. Error is empty if the miner acknowledged the tx.
+type BroadcastResult struct {
+	Ipport string `json:"ipport"`
+	Error  string `json:"error,omitempty"`
+}
+
+//Send the tx to all connected miners and the bootstrap miner in parallel. It succeeds if at least quorum miners
+//acknowledged the tx. The results of all miners are returned in either case.
+func BroadcastTx(ctx context.Context, tx protocol.Transaction, typeID uint8, quorum int) ([]*BroadcastResult, error) {
+	return broadcast(tx.Hash(), quorum, func(dial string) error {
+		return SendTx(ctx, dial, tx, typeID)
+	})
+}
+
+//See BroadcastTx.
+func BroadcastIotTx(ctx context.Context, tx protocol.Iot, typeID uint8, quorum int) ([]*BroadcastResult, error) {
+	return broadcast(tx.Hash(), quorum, func(dial string) error {
+		return SendIotTx(ctx, dial, tx, typeID)
+	})
+}
+
+func broadcast(txHash [32]byte, quorum int, send func(dial string) error) (results []*BroadcastResult, err error) {
+	if quorum <= 0 {
+		return nil, errors.New("Quorum must be > 0.")
+	}
+
+	dials := []string{util.Config.BootstrapIpport}
+	for _, p := range peers.getAllPeers() {
+		if p.dial != util.Config.BootstrapIpport {
+			dials = append(dials, p.dial)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, dial := range dials {
+		result := &BroadcastResult{Ipport: dial}
+		results = append(results, result)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := send(result.Ipport); err != nil {
+				result.Error = err.Error()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	acks := 0
+	for _, result := range results {
+		if len(result.Error) == 0 {
+			acks++
+		}
+	}
+
+	if acks < quorum {
+		return results, errors.New(fmt.Sprintf("Tx %x acknowledged by %v of %v miners, quorum is %v.", txHash[:8], acks, len(results), quorum))
+	}
+
+	return results, nil
+}
@@ -1,6 +1,7 @@
 package network
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/util"
@@ -8,80 +9,118 @@ import (
 	"log"
 	"net"
 	"strconv"
-	"strings"
 	"time"
 )
 
 var (
 	logger     *log.Logger
 	peers      peersStruct
-	register   = make(chan *peer)
 	disconnect = make(chan *peer)
+
+	//Nil if the connections to miners are not encrypted.
+	tlsConfig *tls.Config
 )
 
-func Init() {
+//Returns an error only if the configuration is invalid. Miners which are not reachable are dialed again later.
+func Init() (err error) {
 	logger = util.InitLogger()
 	peers.minerConns = make(map[*peer]bool)
 
+	if tlsConfig, err = util.LoadTLSConfig(); err != nil {
+		return errors.New(fmt.Sprintf("Loading TLS configuration failed: %v", err))
+	}
+
 	go peerService()
-	// TODO Enable again
-	//go checkHealthService()
 
-	p, err := initiateNewClientConnection(util.Config.BootstrapIpport)
-	if err != nil {
-		logger.Fatal("Initiating new network connection failed: ", err)
+	//The bootstrap miner is dialed before returning, so the first requests find a peer. If it is not reachable, the
+	//health service keeps trying.
+	addCandidate(util.Config.BootstrapIpport)
+	wait := connectPeers()
+
+	go checkHealthService(wait)
+
+	return nil
+}
+
+//Open a connection to the miner. It is encrypted if TLS is enabled in the configuration.
+func connect(dial string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   util.FETCH_TIMEOUT * time.Second,
+		KeepAlive: 1 * time.Minute,
 	}
 
-	go minerConn(p)
+	if tlsConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", dial, tlsConfig)
+	}
+
+	return dialer.Dial("tcp", dial)
 }
 
 func initiateNewClientConnection(dial string) (*peer, error) {
 	var conn net.Conn
 
+	//The host is an IPv4 or IPv6 address or a DNS name.
+	_, listenerPort, err := net.SplitHostPort(dial)
+	if err != nil {
+		return nil, err
+	}
+
 	//Open up a tcp dial and instantiate a peer struct, wait for adding it to the peerStruct before we finalize
 	//the handshake
-	conn, err := net.Dial("tcp", dial)
+	conn, err = connect(dial)
 	if err != nil {
 		return nil, err
 	}
 
-	conn.(*net.TCPConn).SetKeepAlive(true)
-	conn.(*net.TCPConn).SetKeepAlivePeriod(1 * time.Minute)
+	//A miner which does not complete the handshake must not block the caller.
+	conn.SetDeadline(time.Now().Add(util.FETCH_TIMEOUT * time.Second))
 
-	p := newPeer(conn, strings.Split(dial, ":")[1])
+	p := newPeer(conn, dial, listenerPort)
 
 	localPort, _ := strconv.Atoi(util.Config.Thisclient.Port)
 	packet, err := p2p.PrepareHandshake(p2p.CLIENT_PING, localPort)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 
+	start := time.Now()
 	conn.Write(packet)
 
 	//Wait for the other party to finish the handshake with the corresponding message
 	header, _, err := rcvData(p)
 	if err != nil || header.TypeID != p2p.CLIENT_PONG {
+		conn.Close()
 		return nil, errors.New(fmt.Sprintf("Failed to complete network handshake: %v", err))
 	}
 
+	//The handshake's round trip time is the first latency sample of the peer.
+	p.recordLatency(time.Since(start))
+	conn.SetDeadline(time.Time{})
+
 	return p, nil
 }
 
-func minerConn(p *peer) {
+//Register the peer, so requests can be sent to it, and start reading its messages.
+func startMinerConn(p *peer) {
 	logger.Printf("Adding a new miner: %v\n", p.getIPPort())
 
 	//Give the peer a channel
 	p.ch = make(chan []byte)
 
-	//Register withe the broadcast service and start the additional writer
-	register <- p
+	peers.add(p)
 
+	go minerConn(p)
+}
+
+func minerConn(p *peer) {
 	for {
 		header, payload, err := rcvData(p)
 		if err != nil {
 			logger.Printf("Miner disconnected: %v\n", err)
 
 			//In case of a comm fail, disconnect cleanly from the broadcast service
+			p.markDead()
 			disconnect <- p
 			return
 		}
@@ -1,23 +1,15 @@
 package network
 
 import (
+	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
 )
 
 var (
 	Uptodate      = false
-	BlockHeaderIn = make(chan *protocol.Block)
-	iplistChan    = make(chan string, p2p.MIN_MINERS)
-
-	BlockChan             = make(chan interface{})
-	BlockHeaderChan       = make(chan interface{})
-	FundsTxChan           = make(chan interface{})
-	AccTxChan             = make(chan interface{})
-	ConfigTxChan          = make(chan interface{})
-	StakeTxChan           = make(chan interface{})
-	AccChan               = make(chan interface{})
-	IntermediateNodesChan = make(chan [][32]byte)
+	BlockHeaderIn = make(chan *protocol.Block, util.MAX_INCOMING_HEADERS)
+	iplistChan    = make(chan string, util.MAX_PEER_CANDIDATES)
 )
 
 func processIncomingMsg(p *peer, header *p2p.Header, payload []byte) {
@@ -45,12 +37,14 @@ func processIncomingMsg(p *peer, header *p2p.Header, payload []byte) {
 	case p2p.STAKETX_RES:
 		txRes(p, payload, p2p.STAKETX_RES)
 	case p2p.ACC_RES:
-		accRes(p, payload)
+		accRes(p, payload, p2p.ACC_RES)
 	case p2p.ROOTACC_RES:
-		accRes(p, payload)
+		accRes(p, payload, p2p.ROOTACC_RES)
 	case p2p.INTERMEDIATE_NODES_RES:
 		intermediateNodesRes(p, payload)
 	case p2p.NEIGHBOR_RES:
 		processNeighborRes(p, payload)
+	case p2p.NOT_FOUND:
+		notFoundRes(p, payload)
 	}
 }
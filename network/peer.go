@@ -1,10 +1,12 @@
 package network
 
 import (
+	"bufio"
+	"github.com/bazo-blockchain/bazo-client/util"
 	"math/rand"
 	"net"
-	"strings"
 	"sync"
+	"time"
 )
 
 //The reason we use an additional listener port is because the port the miner connected to this peer
@@ -12,18 +14,31 @@ import (
 //we send the IP address in p.conn.RemotAddr() with the listenerPort.
 type peer struct {
 	conn         net.Conn
+	//Messages are read through the same reader for the life of the connection, so bytes it read ahead are not lost.
+	reader       *bufio.Reader
 	ch           chan []byte
 	l            sync.Mutex
 	listenerPort string
+
+	//The address the connection was dialed with. Its host is an IP address or a DNS name.
+	dial string
+
+	//Smoothed response time and the number of consecutive requests without response, see score.
+	stats    sync.Mutex
+	latency  time.Duration
+	failures int
+	dead     bool
 }
 
-//Block constructor, argument is the previous block in the blockchain.
-func newPeer(conn net.Conn, listenerPort string) *peer {
+//Peer constructor, dial is the address the connection was opened with.
+func newPeer(conn net.Conn, dial string, listenerPort string) *peer {
 	p := new(peer)
 	p.conn = conn
+	p.reader = bufio.NewReader(conn)
 	p.ch = nil
 	p.l = sync.Mutex{}
 	p.listenerPort = listenerPort
+	p.dial = dial
 
 	return p
 }
@@ -34,61 +49,130 @@ type peersStruct struct {
 	peerMutex  sync.Mutex
 }
 
+//The initial latency is the handshake's round trip time, every response updates the moving average.
+func (p *peer) recordLatency(latency time.Duration) {
+	p.stats.Lock()
+	defer p.stats.Unlock()
+
+	if p.latency == 0 {
+		p.latency = latency
+	} else {
+		p.latency = (p.latency*(util.PEER_LATENCY_WEIGHT-1) + latency) / util.PEER_LATENCY_WEIGHT
+	}
+
+	p.failures = 0
+}
+
+//A peer which did not answer several requests in a row is disconnected. The peer manager replaces it.
+func (p *peer) recordFailure() {
+	p.stats.Lock()
+	p.failures++
+	failures := p.failures
+	p.stats.Unlock()
+
+	if failures >= util.PEER_MAX_FAILURES {
+		logger.Printf("Miner %v did not respond %v times, disconnecting\n", p.getIPPort(), failures)
+		p.markDead()
+	}
+}
+
+//Closing the connection ends the peer's minerConn, which disconnects it from the broadcast service.
+func (p *peer) markDead() {
+	p.stats.Lock()
+	p.dead = true
+	p.stats.Unlock()
+
+	p.conn.Close()
+}
+
+func (p *peer) isDead() bool {
+	p.stats.Lock()
+	defer p.stats.Unlock()
+
+	return p.dead
+}
+
+//Lower is better. Every consecutive failure counts as much as a doubled latency.
+func (p *peer) score() time.Duration {
+	p.stats.Lock()
+	defer p.stats.Unlock()
+
+	return p.latency << uint(p.failures)
+}
+
 func (p *peer) getIPPort() string {
-	ip := strings.Split(p.conn.RemoteAddr().String(), ":")
-	//Cut off original port.
-	port := p.listenerPort
+	ip, _, err := net.SplitHostPort(p.conn.RemoteAddr().String())
+	if err != nil {
+		return p.dial
+	}
 
-	return ip[0] + ":" + port
+	//Cut off original port.
+	return util.JoinIpport(ip, p.listenerPort)
 }
 
-func (peers peersStruct) add(p *peer) {
+func (peers *peersStruct) add(p *peer) {
 	peers.peerMutex.Lock()
 	defer peers.peerMutex.Unlock()
 
 	peers.minerConns[p] = true
 }
 
-func (peers peersStruct) delete(p *peer) {
+func (peers *peersStruct) delete(p *peer) {
 	peers.peerMutex.Lock()
 	defer peers.peerMutex.Unlock()
 
 	delete(peers.minerConns, p)
 }
 
-func (peers peersStruct) len(peerType uint) (length int) {
+func (peers *peersStruct) len() (length int) {
+	peers.peerMutex.Lock()
+	defer peers.peerMutex.Unlock()
+
 	length = len(peers.minerConns)
 
 	return length
 }
 
-func (peers peersStruct) getRandomPeer() (p *peer) {
+//Dead peers are never chosen. Of two randomly picked peers the one with the better score is returned, which prefers
+//fast peers without sending all requests to the same one.
+func (peers *peersStruct) getRandomPeer() (p *peer) {
 	//Acquire list before locking, otherwise deadlock
 	peerList := peers.getAllPeers()
 
 	if len(peerList) == 0 {
 		return nil
-	} else {
-		return peerList[int(rand.Uint32())%len(peerList)]
 	}
+
+	p = peerList[int(rand.Uint32())%len(peerList)]
+	if other := peerList[int(rand.Uint32())%len(peerList)]; other.score() < p.score() {
+		p = other
+	}
+
+	return p
 }
 
-func (peers peersStruct) getAllPeers() []*peer {
+//Returns all peers which are alive.
+func (peers *peersStruct) getAllPeers() []*peer {
 	peers.peerMutex.Lock()
 	defer peers.peerMutex.Unlock()
 
 	var peerList []*peer
 
 	for p := range peers.minerConns {
-		peerList = append(peerList, p)
+		if !p.isDead() {
+			peerList = append(peerList, p)
+		}
 	}
 
 	return peerList
 }
 
-func (peers peersStruct) contains(ipport string) bool {
-	for peer, _ := range peers.minerConns {
-		if peer.getIPPort() == ipport {
+func (peers *peersStruct) contains(ipport string) bool {
+	peers.peerMutex.Lock()
+	defer peers.peerMutex.Unlock()
+
+	for peer := range peers.minerConns {
+		if peer.dial == ipport || peer.getIPPort() == ipport {
 			return true
 		}
 	}
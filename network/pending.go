@@ -0,0 +1,197 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"github.com/bazo-blockchain/bazo-client/util"
+	"sync"
+	"time"
+)
+
+//Responses are matched to the requests waiting for them by the response type and the hash of the requested data.
+//blockHash is only set for intermediate nodes, since a tx hash does not identify its merkle path.
+type requestKey struct {
+	typeID    uint8
+	hash      [32]byte
+	blockHash [32]byte
+}
+
+//Returned if the miner answered that it does not have the requested data, e.g. an unknown account or an account which
+//is not a root account.
+var ErrNotFound = errors.New("Not found.")
+
+//Returned if no response arrived within the fetch timeout.
+var ErrTimeout = errors.New("Fetching timed out.")
+
+//Some responses, e.g. intermediate nodes and NOT_FOUND, do not name the requested data. Since a miner answers the
+//requests of a connection in order, such a response belongs to the oldest matching request sent to the peer which is
+//still unanswered.
+type sentRequest struct {
+	key requestKey
+	ch  chan interface{}
+}
+
+//Thread-safe registry of the requests waiting for a response.
+type pendingRequests struct {
+	waiters map[requestKey][]chan interface{}
+	sent    map[*peer][]sentRequest
+	mutex   sync.Mutex
+}
+
+var pending = &pendingRequests{
+	waiters: make(map[requestKey][]chan interface{}),
+	sent:    make(map[*peer][]sentRequest),
+}
+
+func (r *pendingRequests) register(key requestKey) chan interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	//Buffered, so the delivering reader never blocks on a waiter that timed out in the meantime.
+	ch := make(chan interface{}, 1)
+	r.waiters[key] = append(r.waiters[key], ch)
+
+	return ch
+}
+
+//Remember that the request waiting on ch was sent to p, see sentRequest.
+func (r *pendingRequests) sentTo(p *peer, key requestKey, ch chan interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sent[p] = append(r.sent[p], sentRequest{key, ch})
+}
+
+func (r *pendingRequests) unregister(key requestKey, ch chan interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.removeSent(ch)
+	r.removeWaiter(key, ch)
+}
+
+//Called with the mutex held.
+func (r *pendingRequests) removeWaiter(key requestKey, ch chan interface{}) {
+	waiters := r.waiters[key]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+
+	if len(waiters) == 0 {
+		delete(r.waiters, key)
+	} else {
+		r.waiters[key] = waiters
+	}
+}
+
+//Deliver the payload to all requests waiting for the key. Returns false if nobody waits for it.
+func (r *pendingRequests) deliver(key requestKey, payload interface{}) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	waiters := r.waiters[key]
+	for _, ch := range waiters {
+		select {
+		case ch <- payload:
+		default:
+		}
+
+		r.removeSent(ch)
+	}
+
+	delete(r.waiters, key)
+
+	return len(waiters) > 0
+}
+
+//Deliver the payload to the oldest unanswered request sent to p whose key matches. Used for responses which do not
+//name the requested data. Returns false if no such request waits for p, the response is dropped in this case.
+func (r *pendingRequests) deliverSent(p *peer, match func(key requestKey) bool, payload interface{}) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, request := range r.sent[p] {
+		if !match(request.key) {
+			continue
+		}
+
+		select {
+		case request.ch <- payload:
+		default:
+		}
+
+		r.removeSent(request.ch)
+		r.removeWaiter(request.key, request.ch)
+
+		return true
+	}
+
+	return false
+}
+
+//Deliver ErrNotFound to the oldest unanswered request sent to p. Returns false if no request is waiting for p.
+func (r *pendingRequests) deliverNotFound(p *peer) bool {
+	return r.deliverSent(p, func(key requestKey) bool { return true }, ErrNotFound)
+}
+
+//Called with the mutex held.
+func (r *pendingRequests) removeSent(ch chan interface{}) {
+	for p, sent := range r.sent {
+		for i, request := range sent {
+			if request.ch == ch {
+				sent = append(sent[:i], sent[i+1:]...)
+				break
+			}
+		}
+
+		if len(sent) == 0 {
+			delete(r.sent, p)
+		} else {
+			r.sent[p] = sent
+		}
+	}
+}
+
+//Send the request to a peer and block until the response for key arrives, the request times out or ctx is done.
+//Returns ErrNotFound if the peer does not have the data. Only a request without any response counts as a failure of
+//the peer.
+func (r *pendingRequests) request(ctx context.Context, key requestKey, send func(p *peer)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ch := r.register(key)
+	defer r.unregister(key, ch)
+
+	p := peers.getRandomPeer()
+	if p == nil {
+		return nil, errors.New("Couldn't get a connection, request not transmitted.")
+	}
+
+	//Registered before sending, so a fast NOT_FOUND response finds the request.
+	r.sentTo(p, key, ch)
+
+	start := time.Now()
+	send(p)
+
+	timer := time.NewTimer(util.FETCH_TIMEOUT * time.Second)
+	defer timer.Stop()
+
+	select {
+	case payload := <-ch:
+		p.recordLatency(time.Since(start))
+		if payload == ErrNotFound {
+			return nil, ErrNotFound
+		}
+
+		return payload, nil
+	case <-timer.C:
+		p.recordFailure()
+		return nil, ErrTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/bazo-blockchain/bazo-miner/p2p"
+)
+
+func TestDeliverNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		//Indexes of the requests which are answered before the NOT_FOUND response.
+		answered []int
+		notFound int
+	}{
+		{"oldest request", nil, 0},
+		{"after an answered request", []int{0}, 1},
+		{"after an answer out of order", []int{1}, 0},
+		{"no request left", []int{0, 1, 2}, -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &pendingRequests{waiters: make(map[requestKey][]chan interface{}), sent: make(map[*peer][]sentRequest)}
+			p := &peer{}
+
+			var keys []requestKey
+			var chs []chan interface{}
+			for i := 0; i < 3; i++ {
+				key := requestKey{typeID: p2p.ACC_RES, hash: [32]byte{byte(i)}}
+				ch := r.register(key)
+				r.sentTo(p, key, ch)
+
+				keys = append(keys, key)
+				chs = append(chs, ch)
+			}
+
+			for _, i := range test.answered {
+				r.deliver(keys[i], i)
+				<-chs[i]
+			}
+
+			if delivered := r.deliverNotFound(p); delivered != (test.notFound >= 0) {
+				t.Fatalf("delivered: got %v, want %v", delivered, test.notFound >= 0)
+			}
+
+			for i, ch := range chs {
+				select {
+				case payload := <-ch:
+					if i != test.notFound || payload != ErrNotFound {
+						t.Errorf("request %v got %v", i, payload)
+					}
+				default:
+					if i == test.notFound {
+						t.Errorf("request %v got no response", i)
+					}
+				}
+			}
+
+			if test.notFound >= 0 && len(r.waiters[keys[test.notFound]]) != 0 {
+				t.Error("request answered with NOT_FOUND still waits")
+			}
+		})
+	}
+}
+
+func TestDeliverSent(t *testing.T) {
+	latest := requestKey{typeID: p2p.BlOCK_HEADER_RES}
+	nodes := func(blockHash byte) requestKey {
+		return requestKey{typeID: p2p.INTERMEDIATE_NODES_RES, hash: [32]byte{1}, blockHash: [32]byte{blockHash}}
+	}
+
+	tests := []struct {
+		name string
+		//The requests sent to the asked peer, from the oldest to the youngest.
+		sent      []requestKey
+		responder int
+		match     func(key requestKey) bool
+		delivered int
+	}{
+		{"latest header from the asked peer", []requestKey{latest}, 0, func(key requestKey) bool { return key == latest }, 0},
+		{"latest header from another peer", []requestKey{latest}, 1, func(key requestKey) bool { return key == latest }, -1},
+		{"unsolicited latest header", []requestKey{nodes(1)}, 0, func(key requestKey) bool { return key == latest }, -1},
+		{"intermediate nodes of the oldest block", []requestKey{nodes(1), nodes(2)}, 0, func(key requestKey) bool { return key.typeID == p2p.INTERMEDIATE_NODES_RES }, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &pendingRequests{waiters: make(map[requestKey][]chan interface{}), sent: make(map[*peer][]sentRequest)}
+			peers := []*peer{{}, {}}
+
+			var chs []chan interface{}
+			for _, key := range test.sent {
+				ch := r.register(key)
+				r.sentTo(peers[0], key, ch)
+				chs = append(chs, ch)
+			}
+
+			if delivered := r.deliverSent(peers[test.responder], test.match, "payload"); delivered != (test.delivered >= 0) {
+				t.Fatalf("delivered: got %v, want %v", delivered, test.delivered >= 0)
+			}
+
+			for i, ch := range chs {
+				select {
+				case <-ch:
+					if i != test.delivered {
+						t.Errorf("request %v got the response", i)
+					}
+				default:
+					if i == test.delivered {
+						t.Errorf("request %v got no response", i)
+					}
+				}
+			}
+		})
+	}
+}
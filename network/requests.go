@@ -1,55 +1,32 @@
 package network
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"net"
+	"time"
 )
 
-func BlockReq(blockHash []byte) error {
-	p := peers.getRandomPeer()
-	if p == nil {
-		return errors.New("Couldn't get a connection, request not transmitted.")
-	}
-
+func blockReq(p *peer, blockHash []byte) {
 	packet := p2p.BuildPacket(p2p.BLOCK_REQ, blockHash[:])
 	sendData(p, packet)
-
-	return nil
 }
 
-func BlockHeaderReq(blockHash []byte) error {
-	p := peers.getRandomPeer()
-	if p == nil {
-		return errors.New("Couldn't get a connection, request not transmitted.")
-	}
-
+func blockHeaderReq(p *peer, blockHash []byte) {
 	packet := p2p.BuildPacket(p2p.BLOCK_HEADER_REQ, blockHash[:])
 	sendData(p, packet)
-
-	return nil
 }
 
-func TxReq(txType uint8, txHash [32]byte) error {
-	p := peers.getRandomPeer()
-	if p == nil {
-		return errors.New("Couldn't get a connection, request not transmitted.")
-	}
-
+func txReq(p *peer, txType uint8, txHash [32]byte) {
 	packet := p2p.BuildPacket(txType, txHash[:])
 	sendData(p, packet)
-
-	return nil
 }
 
-func AccReq(root bool, addressHash [32]byte) error {
-	p := peers.getRandomPeer()
-	if p == nil {
-		return errors.New("Couldn't get a connection, request not transmitted.")
-	}
-
+func accReq(p *peer, root bool, addressHash [32]byte) {
 	var packet []byte
 	if root {
 		packet = p2p.BuildPacket(p2p.ROOTACC_REQ, addressHash[:])
@@ -58,80 +35,219 @@ func AccReq(root bool, addressHash [32]byte) error {
 	}
 
 	sendData(p, packet)
+}
 
-	return nil
+//Request the block with the given hash and wait for the response.
+func GetBlock(ctx context.Context, blockHash [32]byte) (*protocol.Block, error) {
+	payload, err := pending.request(ctx, requestKey{typeID: p2p.BLOCK_RES, hash: blockHash}, func(p *peer) {
+		blockReq(p, blockHash[:])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.(*protocol.Block), nil
 }
 
-func SendTx(dial string, tx protocol.Transaction, typeID uint8) (err error) {
-	if conn := p2p.Connect(dial); conn != nil {
-		packet := p2p.BuildPacket(typeID, tx.Encode())
-		conn.Write(packet)
+//Request the header with the given hash and wait for the response.
+func GetBlockHeader(ctx context.Context, blockHash [32]byte) (*protocol.Block, error) {
+	payload, err := pending.request(ctx, requestKey{typeID: p2p.BlOCK_HEADER_RES, hash: blockHash}, func(p *peer) {
+		blockHeaderReq(p, blockHash[:])
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		header, payload, err := p2p.RcvData_(conn)
-		if err != nil || header.TypeID == p2p.NOT_FOUND {
-			err = errors.New(string(payload[:]))
-		}
-		conn.Close()
+	return payload.(*protocol.Block), nil
+}
 
-		return err
+//Request the header of the youngest block and wait for the response.
+func GetLatestBlockHeader(ctx context.Context) (*protocol.Block, error) {
+	payload, err := pending.request(ctx, requestKey{typeID: p2p.BlOCK_HEADER_RES}, func(p *peer) {
+		blockHeaderReq(p, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	txHash := tx.Hash()
-	return errors.New(fmt.Sprintf("Sending tx %x failed.", txHash[:8]))
+	return payload.(*protocol.Block), nil
 }
 
-func SendIotTx(dial string, tx protocol.Iot, typeID uint8) (err error) {
-	if conn := p2p.Connect(dial); conn != nil {
-		packet := p2p.BuildPacket(typeID, tx.Encode())
-		conn.Write(packet)
+//Request the tx with the given hash and wait for the response. txType is one of the p2p tx request types.
+func GetTx(ctx context.Context, txType uint8, txHash [32]byte) (protocol.Transaction, error) {
+	var resType uint8
+	switch txType {
+	case p2p.FUNDSTX_REQ:
+		resType = p2p.FUNDSTX_RES
+	case p2p.ACCTX_REQ:
+		resType = p2p.ACCTX_RES
+	case p2p.CONFIGTX_REQ:
+		resType = p2p.CONFIGTX_RES
+	case p2p.STAKETX_REQ:
+		resType = p2p.STAKETX_RES
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid tx request type %v.", txType))
+	}
+
+	payload, err := pending.request(ctx, requestKey{typeID: resType, hash: txHash}, func(p *peer) {
+		txReq(p, txType, txHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.(protocol.Transaction), nil
+}
+
+//Request the account with the given address hash and wait for the response. If root is set, the account is only
+//returned if it is a root account.
+func GetAccount(ctx context.Context, root bool, addressHash [32]byte) (*protocol.Account, error) {
+	resType := uint8(p2p.ACC_RES)
+	if root {
+		resType = p2p.ROOTACC_RES
+	}
+
+	payload, err := pending.request(ctx, requestKey{typeID: resType, hash: addressHash}, func(p *peer) {
+		accReq(p, root, addressHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.(*protocol.Account), nil
+}
 
-		header, payload, err := p2p.RcvData_(conn)
-		if err != nil || header.TypeID == p2p.NOT_FOUND {
-			err = errors.New(string(payload[:]))
+//Request the intermediate nodes of the merkle path of the tx in the given block and wait for the response.
+func GetIntermediateNodes(ctx context.Context, blockHash [32]byte, txHash [32]byte) ([][32]byte, error) {
+	payload, err := pending.request(ctx, requestKey{typeID: p2p.INTERMEDIATE_NODES_RES, hash: txHash, blockHash: blockHash}, func(p *peer) {
+		intermediateNodesReq(p, blockHash, txHash)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.([][32]byte), nil
+}
+
+//Bind the connection to ctx: an earlier deadline of ctx replaces the fetch timeout and cancelling ctx closes the
+//connection, which unblocks pending reads and writes. The returned function must be called once the connection is not
+//used anymore.
+func bindConn(ctx context.Context, conn net.Conn) (release func()) {
+	deadline := time.Now().Add(util.FETCH_TIMEOUT * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
 		}
+	}()
+
+	return func() {
+		close(done)
 		conn.Close()
+	}
+}
 
+func SendTx(ctx context.Context, dial string, tx protocol.Transaction, typeID uint8) (err error) {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	txHash := tx.Hash()
-	return errors.New(fmt.Sprintf("Sending tx %x failed.", txHash[:8]))
+	conn, err := connect(dial)
+	if err != nil {
+		txHash := tx.Hash()
+		return errors.New(fmt.Sprintf("Sending tx %x failed: %v", txHash[:8], err))
+	}
+
+	release := bindConn(ctx, conn)
+	defer release()
+
+	packet := p2p.BuildPacket(typeID, tx.Encode())
+	conn.Write(packet)
+
+	header, payload, err := p2p.RcvData_(conn)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil || header.TypeID == p2p.NOT_FOUND {
+		err = errors.New(string(payload[:]))
+	}
+
+	return err
 }
 
-func NonVerifiedTxReq(addressHash [32]byte) (nonVerifiedTxs []*protocol.FundsTx) {
-	if conn := p2p.Connect(util.Config.BootstrapIpport); conn != nil {
-		packet := p2p.BuildPacket(p2p.FUNDSTX_REQ, addressHash[:])
-		conn.Write(packet)
+func SendIotTx(ctx context.Context, dial string, tx protocol.Iot, typeID uint8) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		header, payload, err := p2p.RcvData_(conn)
-		if err != nil || header.TypeID != p2p.FUNDSTX_RES {
-			logger.Printf("Requesting non verified tx failed.")
-			return nil
-		}
+	conn, err := connect(dial)
+	if err != nil {
+		txHash := tx.Hash()
+		return errors.New(fmt.Sprintf("Sending tx %x failed: %v", txHash[:8], err))
+	}
 
-		for _, data := range protocol.Decode(payload, protocol.FUNDSTX_SIZE) {
-			var tx *protocol.FundsTx
-			nonVerifiedTxs = append(nonVerifiedTxs, tx.Decode(data))
-		}
+	release := bindConn(ctx, conn)
+	defer release()
+
+	packet := p2p.BuildPacket(typeID, tx.Encode())
+	conn.Write(packet)
+
+	header, payload, err := p2p.RcvData_(conn)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil || header.TypeID == p2p.NOT_FOUND {
+		err = errors.New(string(payload[:]))
 	}
 
-	return nonVerifiedTxs
+	return err
 }
 
-func IntermediateNodesReq(blockHash [32]byte, txHash [32]byte) error {
-	p := peers.getRandomPeer()
-	if p == nil {
-		return errors.New("Couldn't get a connection, request not transmitted.")
+func NonVerifiedTxReq(ctx context.Context, addressHash [32]byte) (nonVerifiedTxs []*protocol.FundsTx) {
+	if ctx.Err() != nil {
+		return nil
 	}
 
+	conn, err := connect(util.Config.BootstrapIpport)
+	if err != nil {
+		logger.Printf("Requesting non verified tx failed: %v\n", err)
+		return nil
+	}
+
+	release := bindConn(ctx, conn)
+	defer release()
+
+	packet := p2p.BuildPacket(p2p.FUNDSTX_REQ, addressHash[:])
+	conn.Write(packet)
+
+	header, payload, err := p2p.RcvData_(conn)
+	if err != nil || header.TypeID != p2p.FUNDSTX_RES {
+		logger.Printf("Requesting non verified tx failed.")
+		return nil
+	}
+
+	for _, data := range protocol.Decode(payload, protocol.FUNDSTX_SIZE) {
+		var tx *protocol.FundsTx
+		nonVerifiedTxs = append(nonVerifiedTxs, tx.Decode(data))
+	}
+
+	return nonVerifiedTxs
+}
+
+func intermediateNodesReq(p *peer, blockHash [32]byte, txHash [32]byte) {
 	var data [][]byte
 	data = append(data, blockHash[:])
 	data = append(data, txHash[:])
 
 	packet := p2p.BuildPacket(p2p.INTERMEDIATE_NODES_REQ, protocol.Encode(data, 32))
 	sendData(p, packet)
-
-	return nil
 }
 
 func neighborReq() {
@@ -2,27 +2,50 @@ package network
 
 import (
 	"encoding/binary"
+	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
 	"github.com/bazo-blockchain/bazo-miner/protocol"
+	"net"
 	"strconv"
 )
 
+//The peer's reader must not wait for the client to process the header, otherwise the responses of the peer stall and
+//its requests time out. If the queue is full, the header is dropped. The next header does not succeed the chain then,
+//so the client syncs and fetches the dropped header.
 func blockHeaderBrdcst(p *peer, payload []byte) {
 	var blockHeader *protocol.Block
-	blockHeader = blockHeader.Decode(payload)
-	BlockHeaderIn <- blockHeader
+	if blockHeader = blockHeader.Decode(payload); blockHeader == nil {
+		return
+	}
+
+	select {
+	case BlockHeaderIn <- blockHeader:
+	default:
+		logger.Printf("Broadcasted header %x dropped, the client is busy.\n", blockHeader.Hash[:8])
+	}
 }
 
 func blockRes(p *peer, payload []byte) {
-	var blockHeader *protocol.Block
-	blockHeader = blockHeader.Decode(payload)
-	BlockChan <- blockHeader
+	var block *protocol.Block
+	if block = block.Decode(payload); block == nil {
+		return
+	}
+
+	pending.deliver(requestKey{typeID: p2p.BLOCK_RES, hash: block.Hash}, block)
 }
 
 func blockHeaderRes(p *peer, payload []byte) {
 	var blockHeader *protocol.Block
-	blockHeader = blockHeader.Decode(payload)
-	BlockHeaderChan <- blockHeader
+	if blockHeader = blockHeader.Decode(payload); blockHeader == nil {
+		return
+	}
+
+	//If nobody requested this header by its hash, it is the response to a request for the latest header. It is only
+	//accepted from a peer which was asked for the latest header, late and unsolicited headers are dropped.
+	if !pending.deliver(requestKey{typeID: p2p.BlOCK_HEADER_RES, hash: blockHeader.Hash}, blockHeader) {
+		latest := requestKey{typeID: p2p.BlOCK_HEADER_RES}
+		pending.deliverSent(p, func(key requestKey) bool { return key == latest }, blockHeader)
+	}
 }
 
 func txRes(p *peer, payload []byte, txType uint8) {
@@ -30,6 +53,7 @@ func txRes(p *peer, payload []byte, txType uint8) {
 		return
 	}
 
+	var tx protocol.Transaction
 	switch txType {
 	case p2p.FUNDSTX_RES:
 		var fundsTx *protocol.FundsTx
@@ -37,38 +61,54 @@ func txRes(p *peer, payload []byte, txType uint8) {
 		if fundsTx == nil {
 			return
 		}
-		FundsTxChan <- fundsTx
+		tx = fundsTx
 	case p2p.ACCTX_RES:
 		var accTx *protocol.AccTx
 		accTx = accTx.Decode(payload)
 		if accTx == nil {
 			return
 		}
-		AccTxChan <- accTx
+		tx = accTx
 	case p2p.CONFIGTX_RES:
 		var configTx *protocol.ConfigTx
 		configTx = configTx.Decode(payload)
 		if configTx == nil {
 			return
 		}
-		ConfigTxChan <- configTx
+		tx = configTx
 	case p2p.STAKETX_RES:
 		var stakeTx *protocol.StakeTx
 		stakeTx = stakeTx.Decode(payload)
 		if stakeTx == nil {
 			return
 		}
-		StakeTxChan <- stakeTx
+		tx = stakeTx
+	default:
+		return
+	}
+
+	pending.deliver(requestKey{typeID: txType, hash: tx.Hash()}, tx)
+}
+
+//The payload is a message of the miner, it does not name the requested data.
+func notFoundRes(p *peer, payload []byte) {
+	if !pending.deliverNotFound(p) {
+		logger.Printf("Unsolicited not found response from %v: %s\n", p.getIPPort(), payload)
 	}
 }
 
-func accRes(p *peer, payload []byte) {
+//Accounts are requested by the hash of their address.
+func accRes(p *peer, payload []byte, accType uint8) {
 	var acc *protocol.Account
-	acc = acc.Decode(payload)
+	if acc = acc.Decode(payload); acc == nil {
+		return
+	}
 
-	AccChan <- acc
+	pending.deliver(requestKey{typeID: accType, hash: protocol.SerializeHashContent(acc.Address)}, acc)
 }
 
+//The response contains neither the block nor the tx hash. It belongs to the oldest request for intermediate nodes sent
+//to the peer, whose tx hash must be the leaf of the first intermediate nodes.
 func intermediateNodesRes(p *peer, payload []byte) {
 	var nodes [][32]byte
 	for _, data := range protocol.Decode(payload, 32) {
@@ -77,7 +117,21 @@ func intermediateNodesRes(p *peer, payload []byte) {
 		nodes = append(nodes, node)
 	}
 
-	IntermediateNodesChan <- nodes
+	checked := false
+	pending.deliverSent(p, func(key requestKey) bool {
+		if key.typeID != p2p.INTERMEDIATE_NODES_RES || checked {
+			return false
+		}
+		checked = true
+
+		if len(nodes) < 2 {
+			return true
+		}
+
+		txHash := key.hash
+		return protocol.SerializeHashContent(append(txHash[:], nodes[0][:]...)) == nodes[1] ||
+			protocol.SerializeHashContent(append(nodes[0][:], txHash[:]...)) == nodes[1]
+	}, nodes)
 }
 
 func processNeighborRes(p *peer, payload []byte) {
@@ -87,28 +141,25 @@ func processNeighborRes(p *peer, payload []byte) {
 
 	for _, ipportIter := range ipportList {
 		logger.Printf("IP/Port received: %v\n", ipportIter)
-		//iplistChan is a buffered channel to handle ips asynchronously.
-		iplistChan <- ipportIter
+		//iplistChan is a buffered channel to handle ips asynchronously. If it is full, the address is dropped instead
+		//of blocking the reader.
+		select {
+		case iplistChan <- ipportIter:
+		default:
+		}
 	}
 }
 
-//Split the processNeighborRes function in two for cleaner testing.
+//Split the processNeighborRes function in two for cleaner testing. The miners only send IPv4 addresses, each followed
+//by the port. IPv6 addresses and DNS names can be configured, but are not discovered through neighbor lists.
 func _processNeighborRes(payload []byte) (ipportList []string) {
 	index := 0
 	for cnt := 0; cnt < len(payload)/(p2p.IPV4ADDR_SIZE+p2p.PORT_SIZE); cnt++ {
-		var addr string
-		for singleAddr := index; singleAddr < index+p2p.IPV4ADDR_SIZE; singleAddr++ {
-			tmp := int(payload[singleAddr])
-			addr += strconv.Itoa(tmp)
-			addr += "."
-		}
-		//Remove trailing dot.
-		addr = addr[:len(addr)-1]
-		addr += ":"
+		ip := net.IP(payload[index : index+p2p.IPV4ADDR_SIZE])
 		//Extract port number.
-		addr += strconv.Itoa(int(binary.BigEndian.Uint16(payload[index+4 : index+6])))
+		port := binary.BigEndian.Uint16(payload[index+p2p.IPV4ADDR_SIZE : index+p2p.IPV4ADDR_SIZE+p2p.PORT_SIZE])
 
-		ipportList = append(ipportList, addr)
+		ipportList = append(ipportList, util.JoinIpport(ip.String(), strconv.Itoa(int(port))))
 		index += p2p.IPV4ADDR_SIZE + p2p.PORT_SIZE
 	}
 	return ipportList
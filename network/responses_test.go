@@ -0,0 +1,67 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bazo-blockchain/bazo-client/util"
+	"github.com/bazo-blockchain/bazo-miner/protocol"
+)
+
+func TestProcessNeighborRes(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    []byte
+		ipportList []string
+	}{
+		{"empty", nil, nil},
+		{"single address", []byte{192, 168, 1, 2, 0x1f, 0x90}, []string{"192.168.1.2:8080"}},
+		{"two addresses", []byte{10, 0, 0, 1, 0, 80, 127, 0, 0, 1, 0xff, 0xff}, []string{"10.0.0.1:80", "127.0.0.1:65535"}},
+		{"incomplete entry", []byte{10, 0, 0, 1, 0, 80, 127, 0, 0}, []string{"10.0.0.1:80"}},
+		{"leading zero byte", []byte{0, 0, 0, 0, 0, 1}, []string{"0.0.0.0:1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ipportList := _processNeighborRes(test.payload); !reflect.DeepEqual(ipportList, test.ipportList) {
+				t.Errorf("got %v, want %v", ipportList, test.ipportList)
+			}
+		})
+	}
+}
+
+func TestBlockHeaderBrdcstDoesNotBlock(t *testing.T) {
+	logger = util.InitLogger()
+	defer func() {
+		for len(BlockHeaderIn) > 0 {
+			<-BlockHeaderIn
+		}
+	}()
+
+	header := &protocol.Block{Hash: [32]byte{1}, Height: 1}
+
+	//Nobody processes the headers, so the queue fills up and further headers are dropped.
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < cap(BlockHeaderIn)+2; i++ {
+			blockHeaderBrdcst(&peer{}, header.EncodeHeader())
+		}
+		blockHeaderBrdcst(&peer{}, []byte{0xff})
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("blockHeaderBrdcst blocked")
+	}
+
+	if len(BlockHeaderIn) != cap(BlockHeaderIn) {
+		t.Errorf("got %v queued headers, want %v", len(BlockHeaderIn), cap(BlockHeaderIn))
+	}
+
+	if received := <-BlockHeaderIn; received == nil || received.Hash != header.Hash {
+		t.Errorf("got header %v", received)
+	}
+}
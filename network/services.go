@@ -5,65 +5,127 @@ import (
 	"time"
 )
 
-//Single goroutine that makes sure the system is well connected.
-func checkHealthService() {
+//A miner the client may connect to. Failed dials are retried with exponential backoff.
+type candidate struct {
+	failures    int
+	nextAttempt time.Time
+}
+
+var (
+	//Only accessed by Init and the health service.
+	candidates = make(map[string]*candidate)
+
+	//Wakes the health service up after a miner disconnected.
+	reconnect = make(chan bool, 1)
+)
+
+//Single goroutine that makes sure the system is well connected. It keeps util.TARGET_MINERS miner connections open,
+//reconnects dropped miners and discovers new ones by asking the connected miners for their neighbors.
+func checkHealthService(wait time.Duration) {
 	for {
-		time.Sleep(util.HEALTH_CHECK_INTERVAL * time.Second)
+		select {
+		case <-time.After(wait):
+		case <-reconnect:
+		}
 
-		//Stop trying to connect after #numberOfRetry attempts.
-		numberOfRetry := 0
+		collectCandidates()
+		wait = connectPeers()
+	}
+}
 
-		if !peers.contains(util.Config.BootstrapIpport) {
-			p, err := initiateNewClientConnection(util.Config.BootstrapIpport)
-			if p == nil || err != nil {
-				logger.Printf("%v\n", err)
-			} else {
-				go minerConn(p)
-			}
+func addCandidate(ipport string) {
+	if _, exists := candidates[ipport]; exists || ipport == util.Config.ThisIpport {
+		return
+	}
+
+	if len(candidates) >= util.MAX_PEER_CANDIDATES {
+		return
+	}
+
+	candidates[ipport] = &candidate{}
+}
+
+//iplistChan gets filled with every incoming neighborRes, they're consumed here.
+func collectCandidates() {
+	for {
+		select {
+		case ipport := <-iplistChan:
+			addCandidate(ipport)
+		default:
+			return
+		}
+	}
+}
+
+//Dial candidates until the target number of connections is reached. Returns the time until the next candidate may be
+//dialed again, but at most the health check interval.
+func connectPeers() (wait time.Duration) {
+	wait = util.HEALTH_CHECK_INTERVAL * time.Second
+
+	for ipport, c := range candidates {
+		if peers.len() >= util.TARGET_MINERS {
+			return wait
 		}
 
-		//Periodically check if we are well-connected
-		if len(peers.minerConns) >= util.MIN_MINERS {
+		if peers.contains(ipport) {
 			continue
 		}
 
-		//The only goto in the code (I promise), but best solution here IMHO.
-	RETRY:
-		select {
-		//iplistChan gets filled with every incoming neighborRes, they're consumed here.
-		case ipaddr := <-iplistChan:
-			p, err := initiateNewClientConnection(ipaddr)
-			if err != nil {
-				logger.Printf("%v\n", err)
+		if now := time.Now(); c.nextAttempt.After(now) {
+			if c.nextAttempt.Sub(now) < wait {
+				wait = c.nextAttempt.Sub(now)
 			}
+			continue
+		}
+
+		p, err := initiateNewClientConnection(ipport)
+		if err != nil {
+			c.failures++
 
-			if p == nil || err != nil {
-				if numberOfRetry < 3 {
-					numberOfRetry++
-					goto RETRY
-				} else {
-					break
-				}
+			//Discovered miners which are not reachable are forgotten, the bootstrap miner is retried forever.
+			if ipport != util.Config.BootstrapIpport && c.failures >= util.PEER_MAX_FAILURES {
+				logger.Printf("Connecting to miner %v failed, dropping it: %v\n", ipport, err)
+				delete(candidates, ipport)
+				continue
 			}
 
-			go minerConn(p)
-			break
-		default:
-			//In case we don't have any ip addresses in the channel left, make a request to the network.
-			neighborReq()
-			break
+			backoff := util.RECONNECT_BACKOFF * time.Second << uint(c.failures-1)
+			if backoff > util.RECONNECT_MAX_BACKOFF*time.Second || backoff <= 0 {
+				backoff = util.RECONNECT_MAX_BACKOFF * time.Second
+			}
+			c.nextAttempt = time.Now().Add(backoff)
+
+			if backoff < wait {
+				wait = backoff
+			}
+
+			logger.Printf("Connecting to miner %v failed, retry in %v: %v\n", ipport, backoff, err)
+			continue
 		}
+
+		c.failures = 0
+		startMinerConn(p)
 	}
+
+	//All candidates are connected or backing off. Ask the connected miners for more.
+	if peers.len() < util.TARGET_MINERS {
+		neighborReq()
+	}
+
+	return wait
 }
 
 func peerService() {
 	for {
 		select {
-		case p := <-register:
-			peers.add(p)
 		case p := <-disconnect:
 			peers.delete(p)
 			close(p.ch)
+
+			select {
+			case reconnect <- true:
+			default:
+			}
 		}
 	}
 }
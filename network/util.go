@@ -5,34 +5,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"github.com/bazo-blockchain/bazo-client/util"
 	"github.com/bazo-blockchain/bazo-miner/p2p"
-	"time"
 )
 
-func Fetch(channelToFetchFrom chan interface{}) (payload interface{}, err error) {
-	select {
-	case payload = <-channelToFetchFrom:
-	case <-time.After(util.FETCH_TIMEOUT * time.Second):
-		return nil, errors.New("Fetching timed out.")
-	}
-
-	return payload, nil
-}
-
-func Fetch32Bytes(channelToFetchFrom chan [][32]byte) (payload [][32]byte, err error) {
-	select {
-	case payload = <-channelToFetchFrom:
-	case <-time.After(util.FETCH_TIMEOUT * time.Second):
-		return nil, errors.New("Fetching timed out.")
-	}
-
-	return payload, nil
-}
-
 func rcvData(p *peer) (header *p2p.Header, payload []byte, err error) {
-	reader := bufio.NewReader(p.conn)
-	header, err = readHeader(reader)
+	header, err = readHeader(p.reader)
 
 	if err != nil {
 		p.conn.Close()
@@ -41,7 +18,7 @@ func rcvData(p *peer) (header *p2p.Header, payload []byte, err error) {
 	payload = make([]byte, header.Len)
 
 	for cnt := 0; cnt < int(header.Len); cnt++ {
-		payload[cnt], err = reader.ReadByte()
+		payload[cnt], err = p.reader.ReadByte()
 		if err != nil {
 			p.conn.Close()
 			return nil, nil, errors.New(fmt.Sprintf("Connection to %v aborted: %v", p.getIPPort(), err))
@@ -87,6 +64,10 @@ func sendData(p *peer, payload []byte) {
 	// logger.Printf("Send message:\nReceiver: %v\nType: %v\nPayload length: %v\n", p.getIPPort(), p2p.LogMapping[payload[4]], len(payload)-p2p.HEADER_LEN)
 
 	p.l.Lock()
-	p.conn.Write(payload)
+	_, err := p.conn.Write(payload)
 	p.l.Unlock()
+
+	if err != nil {
+		p.markDead()
+	}
 }
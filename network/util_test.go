@@ -0,0 +1,46 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func testPacket(typeID uint8, payload []byte) []byte {
+	packet := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(packet[:4], uint32(len(payload)))
+	packet[4] = typeID
+
+	return append(packet, payload...)
+}
+
+func TestRcvDataKeepsReadAhead(t *testing.T) {
+	clientConn, minerConn := net.Pipe()
+	defer clientConn.Close()
+	defer minerConn.Close()
+
+	payloads := [][]byte{{1, 2, 3}, nil, bytes.Repeat([]byte{4}, 5000), {5}}
+
+	//The messages arrive in one write, so the reader buffers the following messages while reading the first one.
+	var stream []byte
+	for i, payload := range payloads {
+		stream = append(stream, testPacket(uint8(i+1), payload)...)
+	}
+
+	go minerConn.Write(stream)
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	p := newPeer(clientConn, "127.0.0.1:8000", "8000")
+	for i, payload := range payloads {
+		header, received, err := rcvData(p)
+		if err != nil {
+			t.Fatalf("message %v: %v", i, err)
+		}
+
+		if header.TypeID != uint8(i+1) || !bytes.Equal(received, payload) {
+			t.Errorf("message %v: got type %v and %v bytes, want type %v and %v bytes", i, header.TypeID, len(received), i+1, len(payload))
+		}
+	}
+}
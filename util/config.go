@@ -3,15 +3,35 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"strings"
 )
 
 const (
 	CONFIGURATION_FILE = "configuration.json"
 
-	HEALTH_CHECK_INTERVAL = 30 //Sec
-	MIN_MINERS            = 1
-	FETCH_TIMEOUT         = 10 //SEC
+	HEALTH_CHECK_INTERVAL  = 30 //Sec
+	TARGET_MINERS          = 3
+	MAX_PEER_CANDIDATES    = 64
+	MAX_INCOMING_HEADERS   = 16
+	PEER_MAX_FAILURES      = 3
+	PEER_LATENCY_WEIGHT    = 4
+	RECONNECT_BACKOFF      = 1   //Sec
+	RECONNECT_MAX_BACKOFF  = 300 //Sec
+	FETCH_TIMEOUT          = 10  //SEC
+	FETCH_RETRIES          = 8
+	FETCH_BACKOFF          = 1   //Sec
+	FETCH_MAX_BACKOFF      = 60  //Sec
+	SYNC_PROGRESS_INTERVAL = 5   //Sec
+	TX_CONFIRMATIONS       = 6
+	TX_WAIT_TIMEOUT        = 600 //Sec
+	TX_TRACKING_BLOCKS     = 1000
+	TXCNT_RESERVATION      = 600 //Sec
+	FEE_ESTIMATE_BLOCKS    = 20
+	FEE_ESTIMATE_TXS       = 200
+	HISTORY_CACHE_SIZE     = 32
+	UNSIGNED_TX_EXPIRY     = 3600 //Sec
 )
 
 var (
@@ -34,6 +54,24 @@ type Configuration struct {
 		Ip   string `json:"ip"`
 		Port string `json:"port"`
 	} `json:"multisig_server"`
+	//Optional, all connections to miners are plaintext TCP if TLS is not enabled.
+	Tls struct {
+		Enabled    bool     `json:"enabled"`
+		CaFile     string   `json:"ca_file"`
+		CertFile   string   `json:"cert_file"`
+		KeyFile    string   `json:"key_file"`
+		ServerName string   `json:"server_name"`
+		PinnedKeys []string `json:"pinned_keys"`
+	} `json:"tls"`
+	//The validators staking since the genesis block. Their stake is not recorded by a StakeTx, so their commitment keys
+	//cannot be derived from the chain.
+	GenesisValidators []GenesisValidator `json:"genesis_validators"`
+}
+
+//The address and the commitment key are hex encoded.
+type GenesisValidator struct {
+	Address       string `json:"address"`
+	CommitmentKey string `json:"commitment_key"`
 }
 
 func LoadConfiguration() (config Configuration) {
@@ -45,8 +83,21 @@ func LoadConfiguration() (config Configuration) {
 	jsonParser := json.NewDecoder(configFile)
 	jsonParser.Decode(&config)
 
-	config.ThisIpport = config.Thisclient.Ip + ":" + config.Thisclient.Port
-	config.BootstrapIpport = config.Bootstrapserver.Ip + ":" + config.Bootstrapserver.Port
-	config.MultisigIpport = config.Multisigserver.Ip + ":" + config.Multisigserver.Port
+	config.ThisIpport = JoinIpport(config.Thisclient.Ip, config.Thisclient.Port)
+	config.BootstrapIpport = JoinIpport(config.Bootstrapserver.Ip, config.Bootstrapserver.Port)
+	config.MultisigIpport = JoinIpport(config.Multisigserver.Ip, config.Multisigserver.Port)
 	return config
 }
+
+//The host is an IPv4 or IPv6 address or a DNS name. IPv6 addresses are enclosed in brackets. Addresses are brought into
+//their canonical form, so the same host always results in the same string.
+func JoinIpport(host string, port string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	} else {
+		host = strings.ToLower(host)
+	}
+
+	return net.JoinHostPort(host, port)
+}
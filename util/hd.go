@@ -0,0 +1,50 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	HD_HARDENED  = 0x80000000
+	HD_PURPOSE   = 44
+	HD_COIN_TYPE = 1 //SLIP-0044 testnet, Bazo has no coin type registered
+)
+
+//Derive the Ed25519 key of the wallet account with the given index from the seed, following SLIP-0010. The path is
+//m/44'/1'/index'. Ed25519 only supports hardened derivation, so all indices are hardened.
+func DeriveEDKey(seed []byte, index uint32) (ed25519.PrivateKey, error) {
+	if index >= HD_HARDENED {
+		return nil, errors.New("invalid argument: index must be < 2^31")
+	}
+
+	key, chainCode := hdMasterKey(seed)
+	for _, child := range []uint32{HD_PURPOSE, HD_COIN_TYPE, index} {
+		key, chainCode = hdChildKey(key, chainCode, child|HD_HARDENED)
+	}
+
+	return ed25519.NewKeyFromSeed(key), nil
+}
+
+func hdMasterKey(seed []byte) (key []byte, chainCode []byte) {
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	return sum[:32], sum[32:]
+}
+
+func hdChildKey(key []byte, chainCode []byte, index uint32) (childKey []byte, childChainCode []byte) {
+	data := make([]byte, 1+32+4)
+	copy(data[1:33], key)
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	return sum[:32], sum[32:]
+}
@@ -0,0 +1,222 @@
+package util
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/scrypt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	KEYSTORE_VERSION = 1
+	KEYSTORE_KDF     = "scrypt"
+	KEYSTORE_CIPHER  = "aes-256-gcm"
+	SCRYPT_N         = 1 << 15
+	SCRYPT_R         = 8
+	SCRYPT_P         = 1
+	SCRYPT_SALT_LEN  = 32
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+
+//An encrypted wallet file. The address is stored in the clear, so the public key can be read without the passphrase.
+//It is authenticated together with the encrypted private key.
+type Keystore struct {
+	Version int    `json:"version"`
+	Address string `json:"address"`
+	EncryptedSecret
+}
+
+//A secret encrypted with AES-256-GCM under a key derived from the passphrase with scrypt.
+type EncryptedSecret struct {
+	Kdf        string       `json:"kdf"`
+	KdfParams  ScryptParams `json:"kdfparams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	Ciphertext string       `json:"ciphertext"`
+}
+
+type ScryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+//Encrypt the private key with a fresh salt and nonce.
+func EncryptKey(privKey ed25519.PrivateKey, passphrase []byte) (*Keystore, error) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key")
+	}
+
+	address := hex.EncodeToString(privKey[32:])
+	encrypted, err := EncryptSecret(privKey, passphrase, []byte(address))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keystore{Version: KEYSTORE_VERSION, Address: address, EncryptedSecret: *encrypted}, nil
+}
+
+//Returns ErrWrongPassphrase if the private key cannot be decrypted or does not belong to the stored address.
+func (keystore *Keystore) Decrypt(passphrase []byte) (ed25519.PrivateKey, error) {
+	if keystore.Version != KEYSTORE_VERSION {
+		return nil, errors.New(fmt.Sprintf("unsupported keystore version %v", keystore.Version))
+	}
+
+	privKey, err := keystore.EncryptedSecret.Decrypt(passphrase, []byte(keystore.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(privKey) != ed25519.PrivateKeySize || hex.EncodeToString(privKey[32:]) != keystore.Address {
+		return nil, ErrWrongPassphrase
+	}
+
+	return privKey, nil
+}
+
+func (keystore *Keystore) PubKey() (ed25519.PublicKey, error) {
+	pubKey, err := hex.DecodeString(keystore.Address)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid keystore: address")
+	}
+
+	return pubKey, nil
+}
+
+//Encrypt the secret with a fresh salt and nonce. The additional data is authenticated, but not encrypted.
+func EncryptSecret(secret []byte, passphrase []byte, additionalData []byte) (*EncryptedSecret, error) {
+	salt := make([]byte, SCRYPT_SALT_LEN)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	encrypted := &EncryptedSecret{
+		Kdf:       KEYSTORE_KDF,
+		KdfParams: ScryptParams{N: SCRYPT_N, R: SCRYPT_R, P: SCRYPT_P, Salt: hex.EncodeToString(salt)},
+		Cipher:    KEYSTORE_CIPHER,
+	}
+
+	aead, err := encrypted.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	encrypted.Nonce = hex.EncodeToString(nonce)
+	encrypted.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, secret, additionalData))
+
+	return encrypted, nil
+}
+
+//Returns ErrWrongPassphrase if the secret cannot be decrypted.
+func (encrypted *EncryptedSecret) Decrypt(passphrase []byte, additionalData []byte) ([]byte, error) {
+	aead, err := encrypted.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(encrypted.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid keystore: nonce")
+	}
+
+	ciphertext, err := hex.DecodeString(encrypted.Ciphertext)
+	if err != nil {
+		return nil, errors.New("invalid keystore: ciphertext")
+	}
+
+	secret, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return secret, nil
+}
+
+func (encrypted *EncryptedSecret) aead(passphrase []byte) (cipher.AEAD, error) {
+	if encrypted.Kdf != KEYSTORE_KDF || encrypted.Cipher != KEYSTORE_CIPHER {
+		return nil, errors.New(fmt.Sprintf("unsupported keystore: kdf %v, cipher %v", encrypted.Kdf, encrypted.Cipher))
+	}
+
+	salt, err := hex.DecodeString(encrypted.KdfParams.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("invalid keystore: salt")
+	}
+
+	key, err := scrypt.Key(passphrase, salt, encrypted.KdfParams.N, encrypted.KdfParams.R, encrypted.KdfParams.P, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+//Wallet files are either keystores or the plaintext files read by crypto.ExtractEDPrivKeyFromFile. Keystores are JSON
+//objects.
+func IsKeystoreFile(filename string) bool {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("{"))
+}
+
+func ReadKeystore(filename string) (*Keystore, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	keystore := new(Keystore)
+	if err := json.Unmarshal(content, keystore); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid keystore %v: %v", filename, err))
+	}
+
+	return keystore, nil
+}
+
+//The keystore is written to a temporary file first, so an existing file is only replaced by a complete keystore.
+func WriteKeystore(filename string, keystore *Keystore) error {
+	content, err := json.MarshalIndent(keystore, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,164 @@
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase []byte
+	}{
+		{"ascii", []byte("correct horse battery staple")},
+		{"empty", []byte{}},
+		{"unicode", []byte("pässwörd 🔑")},
+		{"binary", []byte{0, 1, 2, 0xff}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, privKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keystore, err := EncryptKey(privKey, test.passphrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filename := filepath.Join(t.TempDir(), "wallet.json")
+			if err := WriteKeystore(filename, keystore); err != nil {
+				t.Fatal(err)
+			}
+
+			if !IsKeystoreFile(filename) {
+				t.Errorf("%v is not detected as keystore", filename)
+			}
+
+			read, err := ReadKeystore(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decrypted, err := read.Decrypt(test.passphrase)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(decrypted, privKey) {
+				t.Errorf("decrypted key does not match")
+			}
+
+			pubKey, err := read.PubKey()
+			if err != nil || !bytes.Equal(pubKey, privKey[32:]) {
+				t.Errorf("got public key %x (%v), want %x", pubKey, err, privKey[32:])
+			}
+
+			if _, err := read.Decrypt(append(test.passphrase, 'x')); err != ErrWrongPassphrase {
+				t.Errorf("wrong passphrase: got %v, want %v", err, ErrWrongPassphrase)
+			}
+		})
+	}
+}
+
+func TestKeystoreDecryptFailures(t *testing.T) {
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase := []byte("passphrase")
+	original, err := EncryptKey(privKey, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flip := func(s string) string {
+		if s[0] == '0' {
+			return "1" + s[1:]
+		}
+		return "0" + s[1:]
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(keystore *Keystore)
+		passphrase []byte
+		wantErr    error
+	}{
+		{"wrong passphrase", func(keystore *Keystore) {}, []byte("Passphrase"), ErrWrongPassphrase},
+		{"no passphrase", func(keystore *Keystore) {}, nil, ErrWrongPassphrase},
+		{"modified ciphertext", func(keystore *Keystore) { keystore.Ciphertext = flip(keystore.Ciphertext) }, passphrase, ErrWrongPassphrase},
+		{"modified nonce", func(keystore *Keystore) { keystore.Nonce = flip(keystore.Nonce) }, passphrase, ErrWrongPassphrase},
+		{"modified salt", func(keystore *Keystore) { keystore.KdfParams.Salt = flip(keystore.KdfParams.Salt) }, passphrase, ErrWrongPassphrase},
+		{"replaced address", func(keystore *Keystore) { keystore.Address = flip(keystore.Address) }, passphrase, ErrWrongPassphrase},
+		{"other key's address", func(keystore *Keystore) {
+			other, _ := EncryptKey(otherKey, passphrase)
+			keystore.Address = other.Address
+		}, passphrase, ErrWrongPassphrase},
+		{"unsupported version", func(keystore *Keystore) { keystore.Version = 2 }, passphrase, nil},
+		{"unsupported kdf", func(keystore *Keystore) { keystore.Kdf = "pbkdf2" }, passphrase, nil},
+		{"invalid nonce", func(keystore *Keystore) { keystore.Nonce = "zz" }, passphrase, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keystore := *original
+			test.modify(&keystore)
+
+			privKey, err := keystore.Decrypt(test.passphrase)
+			if err == nil {
+				t.Fatalf("decrypted key %x", privKey[:8])
+			}
+
+			if test.wantErr != nil && err != test.wantErr {
+				t.Errorf("got %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsKeystoreFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		keystore bool
+	}{
+		{"plaintext wallet", "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29\n", false},
+		{"keystore", "{\"version\": 1}", true},
+		{"keystore with leading whitespace", "\n  {\"version\": 1}", true},
+		{"empty file", "", false},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(dir, fmt.Sprint(i))
+			if err := ioutil.WriteFile(filename, []byte(test.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if IsKeystoreFile(filename) != test.keystore {
+				t.Errorf("got %v, want %v", !test.keystore, test.keystore)
+			}
+		})
+	}
+
+	if IsKeystoreFile(filepath.Join(dir, "missing")) {
+		t.Errorf("missing file is detected as keystore")
+	}
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+//Build the TLS configuration for connections to miners. Returns nil if TLS is not enabled.
+//
+//The miner's certificate is verified against the configured CA, or against the system's CAs if none is configured. If
+//public keys are pinned, the miner's certificate must contain one of them. Pinning without a CA accepts self-signed
+//certificates of the pinned keys. A client certificate is presented if one is configured.
+func LoadTLSConfig() (*tls.Config, error) {
+	if !Config.Tls.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: Config.Tls.ServerName,
+	}
+
+	if len(Config.Tls.CaFile) > 0 {
+		caPEM, err := ioutil.ReadFile(Config.Tls.CaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New(fmt.Sprintf("No certificate found in %v", Config.Tls.CaFile))
+		}
+	}
+
+	if len(Config.Tls.CertFile) > 0 || len(Config.Tls.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(Config.Tls.CertFile, Config.Tls.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(Config.Tls.PinnedKeys) > 0 {
+		pins := make(map[[32]byte]bool)
+		for _, pin := range Config.Tls.PinnedKeys {
+			pinBytes, err := hex.DecodeString(strings.TrimSpace(pin))
+			if err != nil || len(pinBytes) != sha256.Size {
+				return nil, errors.New(fmt.Sprintf("Invalid pinned key %v, expected the hex encoded SHA-256 hash of a public key", pin))
+			}
+
+			var hash [32]byte
+			copy(hash[:], pinBytes)
+			pins[hash] = true
+		}
+
+		//Without a CA the chain is not verified, the pin alone authenticates the miner.
+		tlsConfig.InsecureSkipVerify = len(Config.Tls.CaFile) == 0
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("miner presented no certificate")
+			}
+
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+
+			if !pins[sha256.Sum256(cert.RawSubjectPublicKeyInfo)] {
+				return errors.New(fmt.Sprintf("public key of %v is not pinned", cert.Subject))
+			}
+
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,165 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//Create a certificate for miner.example.com. It is self-signed if no parent is given.
+func testCertificate(t *testing.T, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (tls.Certificate, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "miner.example.com"},
+		DNSNames:              []string{"miner.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, cert, key
+}
+
+func pin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(hash[:])
+}
+
+//Run a handshake with a miner presenting the given certificate.
+func testHandshake(t *testing.T, tlsConfig *tls.Config, minerCert tls.Certificate) error {
+	clientConn, minerConn := net.Pipe()
+	defer clientConn.Close()
+	defer minerConn.Close()
+
+	go func() {
+		miner := tls.Server(minerConn, &tls.Config{Certificates: []tls.Certificate{minerCert}})
+		miner.Handshake()
+		miner.Close()
+	}()
+
+	return tls.Client(clientConn, tlsConfig).Handshake()
+}
+
+func TestTLSPinnedKeys(t *testing.T) {
+	defer func(config Configuration) { Config = config }(Config)
+
+	dir := t.TempDir()
+
+	caTLSCert, caCert, caKey := testCertificate(t, true, nil, nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caTLSCert.Certificate[0]}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	selfSigned, selfSignedCert, _ := testCertificate(t, false, nil, nil)
+	other, otherCert, _ := testCertificate(t, false, nil, nil)
+	issued, issuedCert, _ := testCertificate(t, false, caCert, caKey)
+
+	tests := []struct {
+		name      string
+		caFile    string
+		pins      []string
+		minerCert tls.Certificate
+		accepted  bool
+	}{
+		{"pinned self-signed key", "", []string{pin(selfSignedCert)}, selfSigned, true},
+		{"one of several pins", "", []string{pin(otherCert), pin(selfSignedCert)}, selfSigned, true},
+		{"pin with whitespace", "", []string{" " + pin(selfSignedCert) + "\n"}, selfSigned, true},
+		{"key not pinned", "", []string{pin(otherCert)}, selfSigned, false},
+		{"self-signed without pin", "", nil, other, false},
+		{"pinned key issued by the CA", caFile, []string{pin(issuedCert)}, issued, true},
+		{"key issued by the CA, other key pinned", caFile, []string{pin(otherCert)}, issued, false},
+		{"pinned key not issued by the CA", caFile, []string{pin(selfSignedCert)}, selfSigned, false},
+		{"key issued by the CA without pin", caFile, nil, issued, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Config = Configuration{}
+			Config.Tls.Enabled = true
+			Config.Tls.CaFile = test.caFile
+			Config.Tls.ServerName = "miner.example.com"
+			Config.Tls.PinnedKeys = test.pins
+
+			tlsConfig, err := LoadTLSConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := testHandshake(t, tlsConfig, test.minerCert); (err == nil) != test.accepted {
+				t.Errorf("got handshake error %v, want accepted %v", err, test.accepted)
+			}
+		})
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	defer func(config Configuration) { Config = config }(Config)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		caFile  string
+		pins    []string
+		nilConf bool
+		wantErr bool
+	}{
+		{"disabled", false, "", nil, true, false},
+		{"enabled", true, "", nil, false, false},
+		{"pin is not hex", true, "", []string{"zz"}, false, true},
+		{"pin too short", true, "", []string{"abcd"}, false, true},
+		{"missing CA file", true, filepath.Join(t.TempDir(), "missing.pem"), nil, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Config = Configuration{}
+			Config.Tls.Enabled = test.enabled
+			Config.Tls.CaFile = test.caFile
+			Config.Tls.PinnedKeys = test.pins
+
+			tlsConfig, err := LoadTLSConfig()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+
+			if !test.wantErr && (tlsConfig == nil) != test.nilConf {
+				t.Errorf("got config %v", tlsConfig)
+			}
+		})
+	}
+}